@@ -2,9 +2,14 @@ package main
 
 import (
 	"fmt"
+	"maps"
 	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
+	"ansible-hound/core/ansible"
 	"ansible-hound/core/gather"
 	"ansible-hound/core/opengraph"
 
@@ -13,7 +18,10 @@ import (
 )
 
 func launch(client gather.AHClient, targetUrl *url.URL,
-	outdir string, ldap gather.AHLdap, github bool) {
+	outdir string, ldap gather.AHLdap, sharpHoundPaths []string, azureHoundPaths []string,
+	cloudVMKinds map[string]string, cloudPrincipalKinds map[string]string,
+	scmProviders []string, scmHosts map[string]string, azure bool,
+	jobOutputs int, jobOutputMaxSize int64, projectDirs map[string]string) {
 
 	graph := opengraph.InitGraph()
 
@@ -33,6 +41,15 @@ func launch(client gather.AHClient, targetUrl *url.URL,
 		log.Fatalf("Unable to gather Ansible WorX/Tower information (%s).", targetUrl)
 	}
 	instance.Name = targetUrl.Host
+
+	log.Info("Gathering Ansible Worx/Tower settings.")
+	settings, err := gather.GatherSettings(client, *targetUrl)
+	if err != nil {
+		log.Warn("Unable to gather Ansible WorX/Tower settings, the identity used might not be allowed to read them, skipping.")
+		log.Debug(err)
+	}
+	instance.Settings = settings
+
 	instanceNode := instance.ToBHNode()
 	graph.AddNode(instanceNode)
 
@@ -44,13 +61,23 @@ func launch(client gather.AHClient, targetUrl *url.URL,
 		opengraph.AddNodes(&graph, userNodes)
 	}
 
-	hosts, err := gather.GatherHosts(client, instance.InstallUUID, *targetUrl)
+	// NOTE: Inventory Sources are gathered before Host nodes are generated since they set the source of the Hosts.
+	// Host facts are only needed to link Active Directory computers.
+	gatherFacts := ldap != (gather.AHLdap{}) || len(sharpHoundPaths) > 0
+	hosts, hostsErr := gather.GatherHosts(client, instance.InstallUUID, *targetUrl, gatherFacts)
+
+	inventorySources, err := gather.GatherInventorySources(client, instance.InstallUUID, *targetUrl, hosts)
 	if err == nil {
+		inventorySourceNodes := opengraph.GenerateNodes(inventorySources)
+		opengraph.AddNodes(&graph, inventorySourceNodes)
+	}
+
+	if hostsErr == nil {
 		hostNodes := opengraph.GenerateNodes(hosts)
 		opengraph.AddNodes(&graph, hostNodes)
 	}
 
-	groups, err := gather.GatherGroups(client, instance.InstallUUID, *targetUrl)
+	groups, err := gather.GatherGroups(client, instance.InstallUUID, *targetUrl, hosts)
 	if err == nil {
 		groupsNodes := opengraph.GenerateNodes(groups)
 		opengraph.AddNodes(&graph, groupsNodes)
@@ -58,12 +85,23 @@ func launch(client gather.AHClient, targetUrl *url.URL,
 
 	jobs, err := gather.GatherJobs(client, instance.InstallUUID, *targetUrl)
 	if err == nil {
+		if jobOutputs > 0 {
+			gather.GatherJobOutputs(client, *targetUrl, jobs, jobOutputs, jobOutputMaxSize)
+		}
 		jobsNodes := opengraph.GenerateNodes(jobs)
 		opengraph.AddNodes(&graph, jobsNodes)
 	}
 
+	projects, err := gather.GatherProjects(client, instance.InstallUUID, *targetUrl)
+	if err == nil {
+		projectNodes := opengraph.GenerateNodes(projects)
+		opengraph.AddNodes(&graph, projectNodes)
+	}
+
+	// NOTE: Playbooks are analyzed before Job Template nodes are generated since they set their properties.
 	jobTemplates, err := gather.GatherJobTemplates(client, instance.InstallUUID, *targetUrl)
 	if err == nil {
+		gather.AnalyzePlaybooks(projectDirs, projects, jobTemplates)
 		jobTemplatesNodes := opengraph.GenerateNodes(jobTemplates)
 		opengraph.AddNodes(&graph, jobTemplatesNodes)
 	}
@@ -80,7 +118,7 @@ func launch(client gather.AHClient, targetUrl *url.URL,
 		opengraph.AddNodes(&graph, workflowJobTemplateNodeNodes)
 	}
 
-	inventories, err := gather.GatherInventories(client, instance.InstallUUID, *targetUrl)
+	inventories, err := gather.GatherInventories(client, instance.InstallUUID, *targetUrl, hosts, inventorySources)
 	if err == nil {
 		inventoriesNodes := opengraph.GenerateNodes(inventories)
 		opengraph.AddNodes(&graph, inventoriesNodes)
@@ -98,18 +136,14 @@ func launch(client gather.AHClient, targetUrl *url.URL,
 		opengraph.AddNodes(&graph, credentialNodes)
 	}
 
+	credentialInputSources, _ := gather.GatherCredentialInputSources(client, instance.InstallUUID, *targetUrl)
+
 	credentialTypes, err := gather.GatherCredentialTypes(client, instance.InstallUUID, *targetUrl)
 	if err == nil {
 		credentialTypesNodes := opengraph.GenerateNodes(credentialTypes)
 		opengraph.AddNodes(&graph, credentialTypesNodes)
 	}
 
-	projects, err := gather.GatherProjects(client, instance.InstallUUID, *targetUrl)
-	if err == nil {
-		projectNodes := opengraph.GenerateNodes(projects)
-		opengraph.AddNodes(&graph, projectNodes)
-	}
-
 	teams, err := gather.GatherTeams(client, instance.InstallUUID, *targetUrl)
 	if err == nil {
 		teamNodes := opengraph.GenerateNodes(teams)
@@ -123,11 +157,15 @@ func launch(client gather.AHClient, targetUrl *url.URL,
 		jobTemplates, credentials, projects, workflowJobTemplates)
 
 	opengraph.LinkInventory(&graph, inventories,
-		hosts, groups)
+		hosts, groups, inventorySources)
+
+	opengraph.InheritVariables(&graph, inventories, groups, hosts)
 
 	opengraph.LinkJobTemplates(&graph, jobTemplates, jobs,
 		projects, inventories, credentials, credentialTypes)
 
+	opengraph.LinkCredentialInputSources(&graph, credentials, credentialInputSources)
+
 	opengraph.LinkUserRoles(&graph, users, organizations,
 		inventories, teams, credentials,
 		jobTemplates, workflowJobTemplates)
@@ -141,15 +179,53 @@ func launch(client gather.AHClient, targetUrl *url.URL,
 		inventories, projects, organizations, teams)
 
 	opengraph.LinkWorkflowJobTemplates(&graph, workflowJobTemplates,
-		workflowJobTemplateNodes, jobTemplates, inventories)
+		workflowJobTemplateNodes, jobTemplates, inventories, credentials)
+
+	opengraph.LinkTargets(&graph, inventories, hosts, groups,
+		jobTemplates, workflowJobTemplates, workflowJobTemplateNodes)
 
 	// -- Linking Ansible and Active Directory --
 
-	opengraph.LinkAD(&graph, ldap, users)
+	adResolver := gather.InitADResolver(ldap, sharpHoundPaths)
+	if adResolver != nil {
+		defer adResolver.Close()
+	}
+
+	opengraph.LinkAD(&graph, adResolver, users)
+
+	opengraph.LinkADGroups(&graph, adResolver, instance.Settings,
+		organizations, teams)
+
+	opengraph.LinkCredentialsAD(&graph, adResolver, credentials)
+
+	opengraph.LinkHostsAD(&graph, adResolver, hosts)
+
+	// -- Linking Ansible and cloud providers --
+
+	opengraph.LinkCloudHosts(&graph, cloudVMKinds, hosts)
+
+	servicePrincipals := make(map[string]string)
+	if len(azureHoundPaths) > 0 {
+		log.Info("Loading AzureHound data.")
+		servicePrincipals, err = gather.LoadAzureHound(azureHoundPaths)
+		if err != nil {
+			log.Error("Unable to load AzureHound data, skipping resolving service principals.")
+			log.Error(err)
+		}
+	}
+
+	opengraph.LinkCloudCredentials(&graph, cloudPrincipalKinds, servicePrincipals, credentials)
+
+	// -- Linking Ansible and Entra ID --
 
-	// -- Linking Ansible and GitHub --
+	opengraph.LinkAzure(&graph, azure, users)
 
-	opengraph.LinkGitHub(&graph, github, projects, credentials)
+	// -- Linking Ansible and SCM providers --
+
+	opengraph.LinkScm(&graph, scmProviders, scmHosts, projects, credentials)
+
+	opengraph.LinkWebhooks(&graph, scmProviders, scmHosts, projects, jobTemplates,
+		workflowJobTemplates, workflowJobTemplateNodes)
 
 	// -- Output final graph --
 
@@ -157,6 +233,201 @@ func launch(client gather.AHClient, targetUrl *url.URL,
 
 }
 
+func launchFiles(inventoryPaths []string, config map[string]string,
+	outdir string, sharpHoundPaths []string) {
+
+	graph := opengraph.InitGraph()
+
+	// -- Loading inventory files --
+
+	log.Info("Loading inventory files.")
+	inventories, groups, hosts, err := gather.LoadInventoryFiles(inventoryPaths, config)
+	if err != nil {
+		log.Fatalf("Unable to load inventory files.\n%s", err)
+	}
+
+	opengraph.AddNodes(&graph, opengraph.GenerateNodes(inventories))
+	opengraph.AddNodes(&graph, opengraph.GenerateNodes(groups))
+	opengraph.AddNodes(&graph, opengraph.GenerateNodes(hosts))
+
+	// -- Linking Ansible nodes --
+
+	opengraph.LinkInventory(&graph, inventories, hosts, groups, nil)
+
+	opengraph.InheritVariables(&graph, inventories, groups, hosts)
+
+	// -- Linking Ansible and Active Directory --
+
+	adResolver := gather.InitADResolver(gather.AHLdap{}, sharpHoundPaths)
+	if adResolver != nil {
+		defer adResolver.Close()
+	}
+
+	opengraph.LinkHostsAD(&graph, adResolver, hosts)
+
+	// -- Output final graph --
+
+	opengraph.Output(&graph, outdir)
+
+}
+
+func launchExport(exportPaths []string, name string, outdir string,
+	sharpHoundPaths []string, azureHoundPaths []string,
+	cloudVMKinds map[string]string, cloudPrincipalKinds map[string]string,
+	scmProviders []string, scmHosts map[string]string, projectDirs map[string]string) {
+
+	graph := opengraph.InitGraph()
+
+	// -- Loading awx export files --
+
+	log.Info("Loading awx export files.")
+	export, err := gather.LoadAwxExport(exportPaths, name)
+	if err != nil {
+		log.Fatalf("Unable to load awx export files.\n%s", err)
+	}
+
+	gather.AnalyzePlaybooks(projectDirs, export.Projects, export.JobTemplates)
+
+	graph.AddNode(export.Instance.ToBHNode())
+	opengraph.AddNodes(&graph, opengraph.GenerateNodes(export.Organizations))
+	opengraph.AddNodes(&graph, opengraph.GenerateNodes(export.Users))
+	opengraph.AddNodes(&graph, opengraph.GenerateNodes(export.Teams))
+	opengraph.AddNodes(&graph, opengraph.GenerateNodes(export.CredentialTypes))
+	opengraph.AddNodes(&graph, opengraph.GenerateNodes(export.Credentials))
+	opengraph.AddNodes(&graph, opengraph.GenerateNodes(export.Projects))
+	opengraph.AddNodes(&graph, opengraph.GenerateNodes(export.Inventories))
+	opengraph.AddNodes(&graph, opengraph.GenerateNodes(export.Hosts))
+	opengraph.AddNodes(&graph, opengraph.GenerateNodes(export.Groups))
+	opengraph.AddNodes(&graph, opengraph.GenerateNodes(export.JobTemplates))
+	opengraph.AddNodes(&graph, opengraph.GenerateNodes(export.WorkflowJobTemplates))
+	opengraph.AddNodes(&graph, opengraph.GenerateNodes(export.WorkflowJobTemplateNodes))
+
+	// -- Creating Ansible edges --
+
+	opengraph.LinkOrganization(&graph,
+		export.Instance.OID, export.Organizations, export.Inventories,
+		export.JobTemplates, export.Credentials, export.Projects, export.WorkflowJobTemplates)
+
+	opengraph.LinkInventory(&graph, export.Inventories,
+		export.Hosts, export.Groups, nil)
+
+	opengraph.InheritVariables(&graph, export.Inventories, export.Groups, export.Hosts)
+
+	opengraph.LinkJobTemplates(&graph, export.JobTemplates, nil,
+		export.Projects, export.Inventories, export.Credentials, export.CredentialTypes)
+
+	opengraph.LinkCredentialInputSources(&graph, export.Credentials, export.CredentialInputSources)
+
+	opengraph.LinkUserRoles(&graph, export.Users, export.Organizations,
+		export.Inventories, export.Teams, export.Credentials,
+		export.JobTemplates, export.WorkflowJobTemplates)
+
+	opengraph.LinkTeamRoles(&graph, export.Users, export.Organizations,
+		export.Inventories, export.Teams, export.Credentials,
+		export.JobTemplates, export.WorkflowJobTemplates)
+
+	opengraph.LinkAdministrativeRights(&graph, export.Users, export.JobTemplates,
+		export.WorkflowJobTemplates, export.Credentials,
+		export.Inventories, export.Projects, export.Organizations, export.Teams)
+
+	opengraph.LinkWorkflowJobTemplates(&graph, export.WorkflowJobTemplates,
+		export.WorkflowJobTemplateNodes, export.JobTemplates, export.Inventories, export.Credentials)
+
+	opengraph.LinkTargets(&graph, export.Inventories, export.Hosts, export.Groups,
+		export.JobTemplates, export.WorkflowJobTemplates, export.WorkflowJobTemplateNodes)
+
+	// -- Linking Ansible and Active Directory --
+
+	adResolver := gather.InitADResolver(gather.AHLdap{}, sharpHoundPaths)
+	if adResolver != nil {
+		defer adResolver.Close()
+	}
+
+	opengraph.LinkAD(&graph, adResolver, export.Users)
+
+	opengraph.LinkCredentialsAD(&graph, adResolver, export.Credentials)
+
+	opengraph.LinkHostsAD(&graph, adResolver, export.Hosts)
+
+	// -- Linking Ansible and cloud providers --
+
+	// NOTE: Inventory sources are not imported, hosts are only linked to cloud virtual machines
+	// when their inventory source is known.
+	opengraph.LinkCloudHosts(&graph, cloudVMKinds, export.Hosts)
+
+	servicePrincipals := make(map[string]string)
+	if len(azureHoundPaths) > 0 {
+		log.Info("Loading AzureHound data.")
+		servicePrincipals, err = gather.LoadAzureHound(azureHoundPaths)
+		if err != nil {
+			log.Error("Unable to load AzureHound data, skipping resolving service principals.")
+			log.Error(err)
+		}
+	}
+
+	opengraph.LinkCloudCredentials(&graph, cloudPrincipalKinds, servicePrincipals, export.Credentials)
+
+	// -- Linking Ansible and SCM providers --
+
+	opengraph.LinkScm(&graph, scmProviders, scmHosts, export.Projects, export.Credentials)
+
+	opengraph.LinkWebhooks(&graph, scmProviders, scmHosts, export.Projects, export.JobTemplates,
+		export.WorkflowJobTemplates, export.WorkflowJobTemplateNodes)
+
+	// -- Output final graph --
+
+	opengraph.Output(&graph, outdir)
+
+}
+
+// Configures secret detection from the `--keep-secrets` and `--secret-rule` flags.
+func configureSecrets(cmd *cobra.Command) {
+	ansible.Secrets.KeepSecrets, _ = cmd.Flags().GetBool("keep-secrets")
+	secretRules, _ := cmd.Flags().GetStringArray("secret-rule")
+	for _, secretRule := range secretRules {
+		name, pattern, ok := strings.Cut(secretRule, "=")
+		if !ok {
+			log.Fatalf("Invalid secret rule `%s`, expected `name=regex`.", secretRule)
+		}
+		err := ansible.Secrets.AddRule(name, pattern)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+// Returns the SCM providers enabled by their flags and the providers of self-hosted SCM servers.
+func configureScm(cmd *cobra.Command) ([]string, map[string]string) {
+	var scmProviders []string
+	for flag, provider := range map[string]string{
+		"github":       opengraph.GITHUB_PROVIDER,
+		"gitlab":       opengraph.GITLAB_PROVIDER,
+		"bitbucket":    opengraph.BITBUCKET_PROVIDER,
+		"azure-devops": opengraph.AZURE_DEVOPS_PROVIDER,
+	} {
+		if enabled, _ := cmd.Flags().GetBool(flag); enabled {
+			scmProviders = append(scmProviders, provider)
+		}
+	}
+	slices.Sort(scmProviders)
+	scmHosts, _ := cmd.Flags().GetStringToString("scm-hosts")
+	return scmProviders, scmHosts
+}
+
+// Returns the default cloud node kinds overridden by the `--cloud-vm-kinds` and `--cloud-principal-kinds` flags.
+func configureCloudKinds(cmd *cobra.Command) (map[string]string, map[string]string) {
+	cloudVMKinds := make(map[string]string)
+	maps.Copy(cloudVMKinds, opengraph.DEFAULT_CLOUD_VM_KINDS)
+	customCloudVMKinds, _ := cmd.Flags().GetStringToString("cloud-vm-kinds")
+	maps.Copy(cloudVMKinds, customCloudVMKinds)
+
+	cloudPrincipalKinds := make(map[string]string)
+	maps.Copy(cloudPrincipalKinds, opengraph.DEFAULT_CLOUD_PRINCIPAL_KINDS)
+	customCloudPrincipalKinds, _ := cmd.Flags().GetStringToString("cloud-principal-kinds")
+	maps.Copy(cloudPrincipalKinds, customCloudPrincipalKinds)
+	return cloudVMKinds, cloudPrincipalKinds
+}
+
 var ingestCmd = &cobra.Command{
 	Use:   "collect",
 	Short: "Go collector for adding Ansible WorX and Ansible Tower attack paths to BloodHound with OpenGraph ",
@@ -182,25 +453,55 @@ var ingestCmd = &cobra.Command{
 		dc_ipAddress, _ := cmd.Flags().GetString("dc-ip")
 		domain, _ := cmd.Flags().GetString("domain")
 		isLDAPS, _ := cmd.Flags().GetBool("ldaps")
+		isStartTLS, _ := cmd.Flags().GetBool("ldap-starttls")
+		ldapCAFile, _ := cmd.Flags().GetString("ldap-ca-file")
+		ldapSkipVerifySSL, _ := cmd.Flags().GetBool("ldap-skip-verify-ssl")
+
+		ldapBind, _ := cmd.Flags().GetString("ldap-bind")
+		ldapUsername, _ := cmd.Flags().GetString("ldap-user")
+		ldapPassword, _ := cmd.Flags().GetString("ldap-password")
+		ldapHash, _ := cmd.Flags().GetString("ldap-hash")
+		ldapCCache, _ := cmd.Flags().GetString("ldap-ccache")
+		if ldapCCache == "" && ldapBind == gather.BIND_KERBEROS {
+			ldapCCache = strings.TrimPrefix(os.Getenv("KRB5CCNAME"), "FILE:")
+		}
 
-		if (token != "") && (dc_ipAddress != "" || domain != "") {
-			log.Warn("Domain and domain controller address will not be taken into account using token authentication material")
+		// NOTE: Without dedicated LDAP authentication material, the Ansible credentials are reused.
+		if ldapUsername == "" && ldapHash == "" && ldapCCache == "" {
+			ldapUsername = username
+			if ldapPassword == "" {
+				ldapPassword = password
+			}
 		}
+		hasLdapMaterial := ldapCCache != "" || (ldapUsername != "" && (ldapPassword != "" || ldapHash != ""))
 
-		if (token == "") && ((dc_ipAddress == "" && domain != "") || (dc_ipAddress != "" && domain == "")) {
+		if !hasLdapMaterial && (dc_ipAddress != "" || domain != "") {
+			log.Warn("Domain and domain controller address will not be taken into account without LDAP authentication material")
+		}
+
+		if hasLdapMaterial && ((dc_ipAddress == "" && domain != "") || (dc_ipAddress != "" && domain == "" && ldapCCache == "")) {
 			log.Fatal("Invalid domain name or IP provided")
 		}
 
+		if ldapBind != "" && !slices.Contains(gather.BIND_METHODS, ldapBind) {
+			log.Fatalf("Invalid LDAP bind method `%s`, expected one of: %s", ldapBind, strings.Join(gather.BIND_METHODS, ", "))
+		}
+
 		if dc_ipAddress == "" && domain == "" && isLDAPS {
 			log.Warn("The LDAPS parameter will not be taken into account since the domain and the domain controller address are not configured")
 		}
 
+		if isLDAPS && isStartTLS {
+			log.Warn("The StartTLS parameter will not be taken into account since LDAPS is used")
+		}
+
 		verbose, _ := cmd.Flags().GetBool("verbose")
 		if verbose {
 			log.SetLevel(log.DebugLevel)
 		}
 
-		github, _ := cmd.Flags().GetBool("github")
+		scmProviders, scmHosts := configureScm(cmd)
+		azure, _ := cmd.Flags().GetBool("azure")
 
 		var proxyURL *url.URL
 		proxy, _ := cmd.Flags().GetString("proxy")
@@ -219,16 +520,151 @@ var ingestCmd = &cobra.Command{
 
 		var ldap gather.AHLdap
 
-		if dc_ipAddress != "" && domain != "" {
-			ldap = gather.InitLdap(dc_ipAddress, username, password, domain, isLDAPS, skipVerifySSL)
+		if dc_ipAddress != "" && hasLdapMaterial {
+			ldap, err = gather.InitLdap(dc_ipAddress, ldapBind, ldapUsername, ldapPassword,
+				ldapHash, ldapCCache, domain, isLDAPS, isStartTLS, ldapCAFile, ldapSkipVerifySSL)
+			if err != nil {
+				log.Fatalf("Invalid domain controller address specified.\n%s", err)
+			}
+		}
+
+		sharpHoundPaths, _ := cmd.Flags().GetStringSlice("sharphound")
+
+		jobOutputs, _ := cmd.Flags().GetInt("job-outputs")
+		jobOutputMaxSize, _ := cmd.Flags().GetInt64("job-output-max-size")
+
+		configureSecrets(cmd)
+
+		cloudVMKinds, cloudPrincipalKinds := configureCloudKinds(cmd)
+
+		azureHoundPaths, _ := cmd.Flags().GetStringSlice("azurehound")
+
+		projectDirs, _ := cmd.Flags().GetStringToString("project-dir")
+
+		launch(client, targetUrl, outdir, ldap, sharpHoundPaths, azureHoundPaths,
+			cloudVMKinds, cloudPrincipalKinds, scmProviders, scmHosts, azure,
+			jobOutputs, jobOutputMaxSize, projectDirs)
+	},
+}
+
+var filesCmd = &cobra.Command{
+	Use:   "collect-files",
+	Short: "Collect static Ansible inventories (ini or YAML, group_vars, host_vars) and ansible.cfg without an AWX/Tower instance",
+	Run: func(cmd *cobra.Command, args []string) {
+
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		if verbose {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		// NOTE: ansible.cfg is looked up like Ansible does, in ANSIBLE_CONFIG then in the current directory.
+		ansibleCfg, _ := cmd.Flags().GetString("ansible-cfg")
+		if ansibleCfg == "" {
+			ansibleCfg = os.Getenv("ANSIBLE_CONFIG")
+		}
+		if _, err := os.Stat(gather.ANSIBLE_CFG); ansibleCfg == "" && err == nil {
+			ansibleCfg = gather.ANSIBLE_CFG
+		}
+
+		config := make(map[string]string)
+		if ansibleCfg != "" {
+			var err error
+			config, err = gather.LoadAnsibleConfig(ansibleCfg)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+
+		inventoryPaths, _ := cmd.Flags().GetStringSlice("inventory")
+		if len(inventoryPaths) == 0 && config["inventory"] != "" {
+			for _, inventoryPath := range strings.Split(config["inventory"], ",") {
+				inventoryPath = strings.TrimSpace(inventoryPath)
+				if !filepath.IsAbs(inventoryPath) {
+					inventoryPath = filepath.Join(filepath.Dir(ansibleCfg), inventoryPath)
+				}
+				inventoryPaths = append(inventoryPaths, inventoryPath)
+			}
+		}
+		if len(inventoryPaths) == 0 {
+			log.Fatal("No inventory provided, use `--inventory` or the `inventory` setting of ansible.cfg.")
+		}
+
+		configureSecrets(cmd)
+
+		outdir, _ := cmd.Flags().GetString("outdir")
+		sharpHoundPaths, _ := cmd.Flags().GetStringSlice("sharphound")
+
+		launchFiles(inventoryPaths, config, outdir, sharpHoundPaths)
+	},
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "collect-export",
+	Short: "Collect the JSON output of `awx export` without access to the AWX/Tower instance",
+	Run: func(cmd *cobra.Command, args []string) {
+
+		verbose, _ := cmd.Flags().GetBool("verbose")
+		if verbose {
+			log.SetLevel(log.DebugLevel)
+		}
+
+		exportPaths, _ := cmd.Flags().GetStringSlice("file")
+		// NOTE: The flag being required does not prevent an empty value (EX: `--file ""`).
+		if len(exportPaths) == 0 {
+			log.Fatal("No awx export file specified, use --file.")
 		}
 
-		launch(client, targetUrl, outdir, ldap, github)
+		// NOTE: The name of the instance defaults to the name of the first export file.
+		name, _ := cmd.Flags().GetString("name")
+		if name == "" {
+			name = strings.TrimSuffix(filepath.Base(exportPaths[0]), filepath.Ext(exportPaths[0]))
+		}
+
+		configureSecrets(cmd)
+
+		outdir, _ := cmd.Flags().GetString("outdir")
+		sharpHoundPaths, _ := cmd.Flags().GetStringSlice("sharphound")
+		azureHoundPaths, _ := cmd.Flags().GetStringSlice("azurehound")
+		projectDirs, _ := cmd.Flags().GetStringToString("project-dir")
+
+		scmProviders, scmHosts := configureScm(cmd)
+		cloudVMKinds, cloudPrincipalKinds := configureCloudKinds(cmd)
+
+		launchExport(exportPaths, name, outdir, sharpHoundPaths, azureHoundPaths,
+			cloudVMKinds, cloudPrincipalKinds, scmProviders, scmHosts, projectDirs)
 	},
 }
 
 func main() {
 
+	filesCmd.Flags().StringSliceP("inventory", "i", []string{}, "Inventory file or directory (ini or YAML), can be repeated. Defaults to the inventory of ansible.cfg")
+	filesCmd.Flags().StringP("ansible-cfg", "", "", "(optional) Path of ansible.cfg. Defaults to ANSIBLE_CONFIG or ./ansible.cfg")
+	filesCmd.Flags().StringSliceP("sharphound", "", []string{}, "(optional) SharpHound output (computers.json or zip) used to link hosts to Active Directory computers")
+	filesCmd.Flags().StringArrayP("secret-rule", "", []string{}, "(optional) Additional secret detection rule matching values (EX: corp_token=CORP-[0-9a-f]{32}), can be repeated")
+	filesCmd.Flags().BoolP("keep-secrets", "", false, "(optional) Keep the secrets found in variables instead of redacting them")
+	filesCmd.Flags().StringP("outdir", "", "", "(optional) Output directory for the json files.")
+	filesCmd.Flags().BoolP("verbose", "v", false, "(optional) Enable debug logs.")
+	ingestCmd.AddCommand(filesCmd)
+
+	exportCmd.Flags().StringSliceP("file", "f", []string{}, "JSON output of `awx export`, can be repeated when assets were exported separately.")
+	_ = exportCmd.MarkFlagRequired("file")
+	exportCmd.Flags().StringP("name", "", "", "(optional) Name of the exported AWX/Tower instance, objects of exports sharing a name get the same ids. Defaults to the name of the first file")
+	exportCmd.Flags().StringToStringP("project-dir", "", map[string]string{}, "(optional) Local checkout of a project used to analyze its playbooks, per project name or ID (EX: 'Infra Playbooks=./infra'), can be repeated")
+	exportCmd.Flags().StringSliceP("sharphound", "", []string{}, "(optional) SharpHound output (computers.json or zip) used to link hosts to Active Directory computers")
+	exportCmd.Flags().BoolP("github", "", false, "(optional) Enable graphing between Ansible and GitHub")
+	exportCmd.Flags().BoolP("gitlab", "", false, "(optional) Enable graphing between Ansible and GitLab")
+	exportCmd.Flags().BoolP("bitbucket", "", false, "(optional) Enable graphing between Ansible and Bitbucket")
+	exportCmd.Flags().BoolP("azure-devops", "", false, "(optional) Enable graphing between Ansible and Azure DevOps")
+	exportCmd.Flags().StringToStringP("scm-hosts", "", map[string]string{}, "(optional) SCM provider of self-hosted SCM servers (EX: git.corp.local=gitlab)")
+	exportCmd.Flags().StringToStringP("cloud-vm-kinds", "", map[string]string{}, "(optional) Node kind of the cloud virtual machines per inventory source type (EX: ec2=AWSEC2Instance), an empty kind disables the source")
+	exportCmd.Flags().StringToStringP("cloud-principal-kinds", "", map[string]string{}, "(optional) Node kind of the cloud principals per credential identity type (EX: aws_access_key=AWSAccessKey), an empty kind disables the type")
+	exportCmd.Flags().StringSliceP("azurehound", "", []string{}, "(optional) AzureHound output used to resolve the service principals of Azure RM credentials")
+	exportCmd.Flags().StringArrayP("secret-rule", "", []string{}, "(optional) Additional secret detection rule matching values (EX: corp_token=CORP-[0-9a-f]{32}), can be repeated")
+	exportCmd.Flags().BoolP("keep-secrets", "", false, "(optional) Keep the secrets found in variables instead of redacting them")
+	exportCmd.Flags().StringP("outdir", "", "", "(optional) Output directory for the json files.")
+	exportCmd.Flags().BoolP("verbose", "v", false, "(optional) Enable debug logs.")
+	ingestCmd.AddCommand(exportCmd)
+
 	ingestCmd.Flags().StringP("target", "t", "", "Target URL of AWX/Tower instance.")
 	_ = ingestCmd.MarkFlagRequired("target")
 
@@ -236,16 +672,38 @@ func main() {
 	ingestCmd.Flags().StringP("token", "", "", "Token to use for authentication.")
 	ingestCmd.Flags().StringP("password", "p", "", "Password to use for authentication.")
 
-	ingestCmd.Flags().StringP("dc-ip", "", "", "(optional) Target IP of the domain. Required only for LDAP user")
+	ingestCmd.Flags().StringP("dc-ip", "", "", "(optional) Target address of the domain controller (host, host:port, ldap:// or ldaps:// URL). Required only for LDAP user")
 	ingestCmd.Flags().BoolP("ldaps", "", false, "(optional) Configure LDAPS authentication on the domain controller. Required only for LDAP user")
+	ingestCmd.Flags().BoolP("ldap-starttls", "", false, "(optional) Upgrade the LDAP connection to the domain controller using StartTLS")
+	ingestCmd.Flags().StringP("ldap-ca-file", "", "", "(optional) CA bundle (PEM) used to verify the certificate of the domain controller")
+	ingestCmd.Flags().BoolP("ldap-skip-verify-ssl", "", false, "(optional) Skips SSL/TLS verification for LDAP.")
 	ingestCmd.Flags().StringP("domain", "", "", "(optional) NetBIOS domain name. Required only for LDAP user")
+	ingestCmd.Flags().StringP("ldap-bind", "", "", "(optional) LDAP bind method (simple, ntlm, kerberos). Defaults to the available authentication material")
+	ingestCmd.Flags().StringP("ldap-user", "", "", "(optional) Username used to bind on the domain controller. Defaults to the Ansible username")
+	ingestCmd.Flags().StringP("ldap-password", "", "", "(optional) Password used to bind on the domain controller. Defaults to the Ansible password")
+	ingestCmd.Flags().StringP("ldap-hash", "", "", "(optional) NT hash used to bind on the domain controller with NTLM")
+	ingestCmd.Flags().StringP("ldap-ccache", "", "", "(optional) Kerberos ticket cache used to bind on the domain controller with GSSAPI")
+	ingestCmd.Flags().StringSliceP("sharphound", "", []string{}, "(optional) SharpHound output (users.json, groups.json or zip) used to link Active Directory offline instead of the domain controller")
 
 	ingestCmd.Flags().BoolP("github", "", false, "(optional) Enable graphing between Ansible and GitHub")
-
+	ingestCmd.Flags().BoolP("gitlab", "", false, "(optional) Enable graphing between Ansible and GitLab")
+	ingestCmd.Flags().BoolP("bitbucket", "", false, "(optional) Enable graphing between Ansible and Bitbucket")
+	ingestCmd.Flags().BoolP("azure-devops", "", false, "(optional) Enable graphing between Ansible and Azure DevOps")
+	ingestCmd.Flags().StringToStringP("scm-hosts", "", map[string]string{}, "(optional) SCM provider of self-hosted SCM servers (EX: git.corp.local=gitlab)")
+	ingestCmd.Flags().StringToStringP("cloud-vm-kinds", "", map[string]string{}, "(optional) Node kind of the cloud virtual machines per inventory source type (EX: ec2=AWSEC2Instance), an empty kind disables the source")
+	ingestCmd.Flags().StringToStringP("cloud-principal-kinds", "", map[string]string{}, "(optional) Node kind of the cloud principals per credential identity type (EX: aws_access_key=AWSAccessKey), an empty kind disables the type")
+	ingestCmd.Flags().StringSliceP("azurehound", "", []string{}, "(optional) AzureHound output used to resolve the service principals of Azure RM credentials")
+	ingestCmd.Flags().BoolP("azure", "", false, "(optional) Enable graphing between Ansible and Entra ID (SAML/OIDC users)")
+
+	ingestCmd.Flags().StringArrayP("secret-rule", "", []string{}, "(optional) Additional secret detection rule matching values (EX: corp_token=CORP-[0-9a-f]{32}), can be repeated")
+	ingestCmd.Flags().BoolP("keep-secrets", "", false, "(optional) Keep the secrets found in variables instead of redacting them")
+	ingestCmd.Flags().IntP("job-outputs", "", 0, "(optional) Download and scan the output of the N most recent Jobs of each template for secrets")
+	ingestCmd.Flags().Int64P("job-output-max-size", "", 1048576, "(optional) Maximum size in bytes of each downloaded Job output")
+	ingestCmd.Flags().StringToStringP("project-dir", "", map[string]string{}, "(optional) Local checkout of a project used to analyze its playbooks, per project name or ID (EX: 'Infra Playbooks=./infra'), can be repeated")
 	ingestCmd.Flags().StringP("proxy", "", "", "(optional) Configure HTTP/HTTPS proxy.")
 	ingestCmd.Flags().StringP("outdir", "", "", "(optional) Output directory for the json files.")
 	ingestCmd.Flags().BoolP("verbose", "v", false, "(optional) Enable debug logs.")
-	ingestCmd.Flags().BoolP("skip-verify-ssl", "k", false, "(optional) Skips SSL/TLS verification for HTTP and LDAP.")
+	ingestCmd.Flags().BoolP("skip-verify-ssl", "k", false, "(optional) Skips SSL/TLS verification for HTTP.")
 
 	if err := ingestCmd.Execute(); err != nil {
 		msg := fmt.Sprintf("CLI error: %v\n", err)
@@ -40,6 +40,16 @@ func (o *Object) InitOID(installUUID string) {
 	o.OID = hex.EncodeToString(hashBytes)
 }
 
+// Initializes the OID of objects collected without an Ansible instance (EX: inventory files),
+// which have no stable ID. The OID is derived from a namespace, the type and the name.
+func (o *Object) InitNamedOID(namespace string) {
+	data := fmt.Sprintf("%s_%s_%s", namespace, o.Type, o.Name)
+	hasher := sha1.New()
+	hasher.Write([]byte(data))
+	hashBytes := hasher.Sum(nil)
+	o.OID = hex.EncodeToString(hashBytes)
+}
+
 type AnsibleType interface {
 	GetID() int
 	GetOID() string
@@ -54,9 +64,10 @@ type Response[T any] struct {
 
 type AnsibleInstance struct {
 	Object
-	Version     string `json:"version"`
-	ActiveNode  string `json:"active_node"`
-	InstallUUID string `json:"install_uuid"`
+	Version     string    `json:"version"`
+	ActiveNode  string    `json:"active_node"`
+	InstallUUID string    `json:"install_uuid"`
+	Settings    *Settings `json:"settings,omitempty"`
 }
 
 func (i *AnsibleInstance) MarshalJSON() ([]byte, error) {
@@ -70,6 +81,11 @@ func (i *AnsibleInstance) ToBHNode() (n *node.Node) {
 	props.SetProperty("version", i.Version)
 	props.SetProperty("active_node", i.ActiveNode)
 	props.SetProperty("install_uuid", i.InstallUUID)
+	if i.Settings != nil {
+		i.Settings.SetProperties(props)
+	} else {
+		props.SetProperty("settings_collected", strconv.FormatBool(false))
+	}
 	n, _ = node.NewNode(i.OID, []string{"ATAnsibleInstance"}, props)
 
 	return n
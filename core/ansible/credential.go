@@ -2,6 +2,9 @@ package ansible
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -9,6 +12,33 @@ import (
 	"github.com/Ramoreik/gopengraph/properties"
 )
 
+const GALAXY_CREDENTIAL_KIND = "galaxy_api_token"
+const AWS_CREDENTIAL_KIND = "aws"
+const AZURE_RM_CREDENTIAL_KIND = "azure_rm"
+const GCE_CREDENTIAL_KIND = "gce"
+const OPENSTACK_CREDENTIAL_KIND = "openstack"
+
+// Credential types looking up secrets in an external secret manager.
+var SECRET_MANAGER_CREDENTIAL_KINDS = []string{
+	"hashivault_kv", "hashivault_ssh", "azure_kv", "conjur", "aim",
+	"thycotic_dsv", "thycotic_tss", "centrify_vault", "aws_secretsmanager_credential",
+}
+
+// Types of the cloud principals a credential authenticates as.
+const AWS_ACCESS_KEY_IDENTITY = "aws_access_key"
+const AZURE_SERVICE_PRINCIPAL_IDENTITY = "azure_service_principal"
+const AZURE_USER_IDENTITY = "azure_user"
+const GCE_SERVICE_ACCOUNT_IDENTITY = "gce_service_account"
+const OPENSTACK_USER_IDENTITY = "openstack_user"
+
+// Servers used by AWX/Tower when no custom Galaxy or Automation Hub server is configured,
+// URLs under these prefixes (EX: Automation Hub content paths) are default servers too.
+var DefaultGalaxyServers = []string{
+	"https://galaxy.ansible.com",
+	"https://console.redhat.com/api/automation-hub",
+	"https://cloud.redhat.com/api/automation-hub",
+}
+
 type Credential struct {
 	Object
 	Organization   int            `json:"organization"`
@@ -43,11 +73,151 @@ func (c *Credential) ToBHNode() (n *node.Node) {
 		props.SetProperty("username", username)
 	}
 
+	if c.Kind == GALAXY_CREDENTIAL_KIND {
+		if galaxyUrl, ok := c.Inputs["url"].(string); ok {
+			props.SetProperty("galaxy_url", galaxyUrl)
+			props.SetProperty("galaxy_custom_server", strconv.FormatBool(IsCustomGalaxyServer(galaxyUrl)))
+		}
+	}
+
+	switch c.Kind {
+	case AWS_CREDENTIAL_KIND:
+		props.SetProperty("aws_access_key_id", c.input("username"))
+	case AZURE_RM_CREDENTIAL_KIND:
+		props.SetProperty("azure_client_id", c.input("client"))
+		props.SetProperty("azure_tenant_id", c.input("tenant"))
+		props.SetProperty("azure_subscription_id", c.input("subscription"))
+	case GCE_CREDENTIAL_KIND:
+		props.SetProperty("gce_service_account_email", c.input("username"))
+		props.SetProperty("gce_project", c.input("project"))
+	case OPENSTACK_CREDENTIAL_KIND:
+		props.SetProperty("openstack_host", c.input("host"))
+		props.SetProperty("openstack_project", c.input("project"))
+		props.SetProperty("openstack_domain", c.input("domain"))
+	}
+
+	if slices.Contains(SECRET_MANAGER_CREDENTIAL_KINDS, c.Kind) {
+		secretManagerUrl, namespace, authMethod := c.SecretManager()
+		props.SetProperty("secret_manager", strconv.FormatBool(true))
+		props.SetProperty("secret_manager_url", secretManagerUrl)
+		props.SetProperty("secret_manager_namespace", namespace)
+		props.SetProperty("secret_manager_auth_method", authMethod)
+	}
+
+	if identity, ok := c.CloudIdentity(); ok {
+		props.SetProperty("cloud_identity_type", identity.Type)
+		props.SetProperty("cloud_identity", identity.Identifier)
+	}
+
 	n, _ = node.NewNode(c.OID, []string{"ATCredential"}, props)
 
 	return n
 }
 
+// Returns a non-secret input of the credential, secret inputs are returned as `$encrypted$` by AWX/Tower.
+func (c *Credential) input(name string) string {
+	value, _ := c.Inputs[name].(string)
+	return value
+}
+
+// Returns true when a secret input is set, AWX/Tower replaces secret values with `$encrypted$`.
+func (c *Credential) hasInput(name string) bool {
+	return c.input(name) != ""
+}
+
+// Returns the URL, the namespace (or account, domain) and the authentication method
+// of a credential looking up secrets in an external secret manager.
+func (c *Credential) SecretManager() (secretManagerUrl string, namespace string, authMethod string) {
+	switch c.Kind {
+	case "hashivault_kv", "hashivault_ssh":
+		secretManagerUrl, namespace = c.input("url"), c.input("namespace")
+		switch {
+		case c.hasInput("token"):
+			authMethod = "token"
+		case c.hasInput("role_id"):
+			authMethod = "approle"
+		case c.hasInput("kubernetes_role"):
+			authMethod = "kubernetes"
+		case c.hasInput("client_cert_public"):
+			authMethod = "cert"
+		case c.hasInput("username"):
+			authMethod = "userpass"
+		}
+	case "azure_kv":
+		secretManagerUrl, namespace = c.input("url"), c.input("tenant")
+		authMethod = "managed_identity"
+		if c.hasInput("client") {
+			authMethod = "client_secret"
+		}
+	case "conjur":
+		secretManagerUrl, namespace, authMethod = c.input("url"), c.input("account"), "api_key"
+	case "aim":
+		secretManagerUrl, namespace = c.input("url"), c.input("app_id")
+		authMethod = "client_certificate"
+		if c.hasInput("webservice_id") && !c.hasInput("client_cert") {
+			authMethod = "none"
+		}
+	case "thycotic_dsv":
+		secretManagerUrl = c.input("tenant") + "." + c.input("tld")
+		namespace, authMethod = c.input("tenant"), "client_credentials"
+	case "thycotic_tss":
+		secretManagerUrl, namespace = c.input("server_url"), c.input("domain")
+		authMethod = "password"
+		if c.hasInput("token") {
+			authMethod = "token"
+		}
+	case "centrify_vault":
+		secretManagerUrl, namespace, authMethod = c.input("url"), c.input("oauth_application_id"), "client_credentials"
+	case "aws_secretsmanager_credential":
+		secretManagerUrl, namespace, authMethod = c.input("aws_region"), c.input("aws_access_key"), "access_key"
+	}
+	return secretManagerUrl, namespace, authMethod
+}
+
+type CloudIdentity struct {
+	Type       string
+	Identifier string
+	Tenant     string
+}
+
+// Returns the cloud principal a credential of a managed cloud type authenticates as,
+// identified from its non-secret inputs.
+func (c *Credential) CloudIdentity() (identity CloudIdentity, ok bool) {
+	switch c.Kind {
+	case AWS_CREDENTIAL_KIND:
+		identity = CloudIdentity{Type: AWS_ACCESS_KEY_IDENTITY, Identifier: c.input("username")}
+	case AZURE_RM_CREDENTIAL_KIND:
+		// NOTE: Azure RM credentials either hold a service principal (client) or a user.
+		if c.input("client") != "" {
+			identity = CloudIdentity{Type: AZURE_SERVICE_PRINCIPAL_IDENTITY, Identifier: c.input("client"), Tenant: c.input("tenant")}
+		} else {
+			identity = CloudIdentity{Type: AZURE_USER_IDENTITY, Identifier: c.input("username")}
+		}
+	case GCE_CREDENTIAL_KIND:
+		identity = CloudIdentity{Type: GCE_SERVICE_ACCOUNT_IDENTITY, Identifier: c.input("username")}
+	case OPENSTACK_CREDENTIAL_KIND:
+		identity = CloudIdentity{Type: OPENSTACK_USER_IDENTITY, Identifier: c.input("username"), Tenant: c.input("domain")}
+	}
+	return identity, identity.Identifier != ""
+}
+
+// Returns true when the URL is not served by a default Galaxy or Automation Hub server,
+// servers are compared on their scheme, host and path prefix.
+func IsCustomGalaxyServer(galaxyUrl string) bool {
+	parsed, err := url.Parse(strings.ToLower(strings.TrimSpace(galaxyUrl)))
+	if err != nil {
+		return true
+	}
+	for _, defaultServer := range DefaultGalaxyServers {
+		server, _ := url.Parse(defaultServer)
+		if parsed.Scheme == server.Scheme && parsed.Host == server.Host &&
+			strings.HasPrefix(strings.TrimSuffix(parsed.Path, "/")+"/", server.Path+"/") {
+			return false
+		}
+	}
+	return true
+}
+
 type CredentialType struct {
 	Object
 	Managed    bool           `json:"managed,omitempty"`
@@ -135,3 +305,41 @@ func (ct *CredentialType) ToBHNode() (n *node.Node) {
 
 	return n
 }
+
+// CredentialInputSource fills an input of the target credential with a secret
+// looked up through the source credential.
+type CredentialInputSource struct {
+	Object
+	InputFieldName   string         `json:"input_field_name"`
+	Metadata         map[string]any `json:"metadata,omitempty"`
+	TargetCredential int            `json:"target_credential"`
+	SourceCredential int            `json:"source_credential"`
+}
+
+func (c CredentialInputSource) MarshalJSON() ([]byte, error) {
+	type credentialInputSource CredentialInputSource
+	return json.MarshalIndent((credentialInputSource)(c), "", "  ")
+}
+
+// NOTE: Input sources are represented as edges between credentials, the node is not added to the graph.
+func (c *CredentialInputSource) ToBHNode() (n *node.Node) {
+	props := properties.NewProperties()
+	props.SetProperty("id", strconv.Itoa(c.ID))
+	props.SetProperty("input_field_name", c.InputFieldName)
+	props.SetProperty("target_credential", strconv.Itoa(c.TargetCredential))
+	props.SetProperty("source_credential", strconv.Itoa(c.SourceCredential))
+	n, _ = node.NewNode(c.OID, []string{"ATCredentialInputSource"}, props)
+
+	return n
+}
+
+// Returns the metadata of the lookup (EX: `secret_path`, `secret_key`) as strings.
+func (c *CredentialInputSource) MetadataProperties() map[string]string {
+	metadata := make(map[string]string)
+	for key, value := range c.Metadata {
+		if value != nil {
+			metadata[key] = fmt.Sprint(value)
+		}
+	}
+	return metadata
+}
@@ -0,0 +1,32 @@
+package ansible
+
+import (
+	"testing"
+)
+
+func TestIsCustomGalaxyServer(t *testing.T) {
+	tests := []struct {
+		url    string
+		custom bool
+	}{
+		{"https://galaxy.ansible.com", false},
+		{"https://galaxy.ansible.com/", false},
+		{"https://galaxy.ansible.com/api/", false},
+		{" HTTPS://Galaxy.Ansible.com ", false},
+		{"https://console.redhat.com/api/automation-hub/", false},
+		{"https://console.redhat.com/api/automation-hub/content/published/", false},
+		{"https://console.redhat.com/api/automation-hub/content/validated/", false},
+		{"https://cloud.redhat.com/api/automation-hub/content/published/", false},
+		{"https://console.redhat.com/api/automation-hubs/", true},
+		{"https://console.redhat.com/", true},
+		{"http://galaxy.ansible.com", true},
+		{"https://galaxy.ansible.com.evil.corp", true},
+		{"https://hub.corp.local/api/galaxy/content/published/", true},
+		{"://invalid", true},
+	}
+	for _, test := range tests {
+		if custom := IsCustomGalaxyServer(test.url); custom != test.custom {
+			t.Errorf("IsCustomGalaxyServer(%q) = %t, expected %t", test.url, custom, test.custom)
+		}
+	}
+}
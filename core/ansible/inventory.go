@@ -2,27 +2,40 @@ package ansible
 
 import (
 	"encoding/json"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/Ramoreik/gopengraph/node"
 	"github.com/Ramoreik/gopengraph/properties"
 )
 
+const SMART_INVENTORY_KIND = "smart"
+const CONSTRUCTED_INVENTORY_KIND = "constructed"
+const CONSTRUCTED_INVENTORY_SOURCE = "constructed"
+const INVENTORY_SOURCE_NEVER_UPDATED_STATUS = "never updated"
+
 type Inventory struct {
 	Object
-	Organization                 int    `json:"organization"`
-	Kind                         string `json:"kind,omitempty"`
-	HostFilter                   string `json:"host_filder,omitempty"`
-	Variables                    string `json:"variables,omitempty"`
-	HasActiveFailures            bool   `json:"has_active_failures,omitempty"`
-	TotalHosts                   int    `json:"total_hosts,omitempty"`
-	HostsWithActiveFailures      int    `json:"host_with_active_failures,omitempty"`
-	TotalGroups                  int    `json:"total_groups,omitempty"`
-	HasInventorySources          bool   `json:"has_inventory_sources,omitempty"`
-	TotalInventorySources        int    `json:"total_inventory_sources,omitempty"`
-	InventorySourcesWithFailures int    `json:"inventory_sources_with_failures,omitempty"`
-	PendingDeletion              bool   `json:"pending_deletion,omitempty"`
-	PreventInstanceGroupFallback bool   `json:"prevent_instance_group_fallback,omitempty"`
+	Organization                 int                `json:"organization"`
+	Kind                         string             `json:"kind,omitempty"`
+	HostFilter                   string             `json:"host_filter,omitempty"`
+	Variables                    string             `json:"variables,omitempty"`
+	HasActiveFailures            bool               `json:"has_active_failures,omitempty"`
+	TotalHosts                   int                `json:"total_hosts,omitempty"`
+	HostsWithActiveFailures      int                `json:"host_with_active_failures,omitempty"`
+	TotalGroups                  int                `json:"total_groups,omitempty"`
+	HasInventorySources          bool               `json:"has_inventory_sources,omitempty"`
+	TotalInventorySources        int                `json:"total_inventory_sources,omitempty"`
+	InventorySourcesWithFailures int                `json:"inventory_sources_with_failures,omitempty"`
+	PendingDeletion              bool               `json:"pending_deletion,omitempty"`
+	PreventInstanceGroupFallback bool               `json:"prevent_instance_group_fallback,omitempty"`
+	Limit                        string             `json:"limit,omitempty"`
+	SourceVars                   string             `json:"source_vars,omitempty"`
+	InputInventories             map[int]*Inventory `json:"input_inventories,omitempty"`
+	Hosts                        map[int]*Host      `json:"hosts,omitempty"`
+	AnsibleConfig                map[string]string  `json:"ansible_config,omitempty"`
+	ConfigVariables              string             `json:"-"`
 }
 
 func (i Inventory) MarshalJSON() ([]byte, error) {
@@ -39,6 +52,13 @@ func (i *Inventory) ToBHNode() (n *node.Node) {
 	props.SetProperty("organization", strconv.FormatInt(int64(i.Organization), 10))
 	props.SetProperty("kind", i.Kind)
 	props.SetProperty("host_filter", i.HostFilter)
+	props.SetProperty("limit", i.Limit)
+	props.SetProperty("source_vars", i.SourceVars)
+	Secrets.SetVariablesProperty(props, "variables", i.Variables)
+	setVaultProperties(props, i.Variables)
+	for key, value := range i.AnsibleConfig {
+		props.SetProperty("ansible_cfg_"+key, value)
+	}
 	props.SetProperty("has_active_failures", strconv.FormatBool(i.HasActiveFailures))
 	props.SetProperty("has_inventory_source", strconv.FormatBool(i.HasInventorySources))
 	props.SetProperty("total_hosts", strconv.FormatInt(int64(i.TotalHosts), 10))
@@ -53,16 +73,82 @@ func (i *Inventory) ToBHNode() (n *node.Node) {
 	return n
 }
 
+type InventorySource struct {
+	Object
+	Inventory      int    `json:"inventory,omitempty"`
+	Source         string `json:"source,omitempty"`
+	SourcePath     string `json:"source_path,omitempty"`
+	SourceProject  int    `json:"source_project,omitempty"`
+	Credential     int    `json:"credential,omitempty"`
+	Overwrite      bool   `json:"overwrite,omitempty"`
+	OverwriteVars  bool   `json:"overwrite_vars,omitempty"`
+	UpdateOnLaunch bool   `json:"update_on_launch,omitempty"`
+	Status         string `json:"status,omitempty"`
+	LastUpdated    string `json:"last_updated,omitempty"`
+}
+
+// Returns true when the source was never synced, the hosts it selects are not known yet.
+func (i *InventorySource) IsNeverUpdated() bool {
+	return i.Status == INVENTORY_SOURCE_NEVER_UPDATED_STATUS || i.LastUpdated == ""
+}
+
+func (i InventorySource) MarshalJSON() ([]byte, error) {
+	type inventorySource InventorySource
+	return json.MarshalIndent((inventorySource)(i), "", "  ")
+}
+
+func (i *InventorySource) ToBHNode() (n *node.Node) {
+	props := properties.NewProperties()
+	props.SetProperty("id", strconv.Itoa(i.ID))
+	props.SetProperty("name", i.Name)
+	props.SetProperty("description", i.Description)
+	props.SetProperty("url", i.Url)
+	props.SetProperty("type", i.Type)
+	props.SetProperty("created", i.Created)
+	props.SetProperty("modified", i.Modified)
+	props.SetProperty("inventory", strconv.FormatInt(int64(i.Inventory), 10))
+	props.SetProperty("source", i.Source)
+	props.SetProperty("source_path", i.SourcePath)
+	props.SetProperty("source_project", strconv.FormatInt(int64(i.SourceProject), 10))
+	props.SetProperty("credential", strconv.FormatInt(int64(i.Credential), 10))
+	props.SetProperty("status", i.Status)
+	props.SetProperty("last_updated", i.LastUpdated)
+	props.SetProperty("overwrite", strconv.FormatBool(i.Overwrite))
+	props.SetProperty("overwrite_vars", strconv.FormatBool(i.OverwriteVars))
+	props.SetProperty("update_on_launch", strconv.FormatBool(i.UpdateOnLaunch))
+	n, _ = node.NewNode(i.OID, []string{"ATInventorySource"}, props)
+
+	return n
+}
+
 type Host struct {
 	Object
-	Inventory            int    `json:"inventory,omitempty"`
-	Enabled              bool   `json:"enabled,omitempty"`
-	InstanceId           string `json:"instance_id,omitempty"`
-	Variables            string `json:"variables,omitempty"`
-	HasActiveFailures    bool   `json:"has_active_failures,omitempty"`
-	LastJob              int    `json:"last_job,omitempty"`
-	LastJobHostSummary   int    `json:"last_job_host_summary,omitempty"`
-	AnsibleFactsModified string `json:"ansible_facts_modified,omitempty"`
+	Inventory            int               `json:"inventory,omitempty"`
+	Enabled              bool              `json:"enabled,omitempty"`
+	InstanceId           string            `json:"instance_id,omitempty"`
+	Variables            string            `json:"variables,omitempty"`
+	HasActiveFailures    bool              `json:"has_active_failures,omitempty"`
+	LastJob              int               `json:"last_job,omitempty"`
+	LastJobHostSummary   int               `json:"last_job_host_summary,omitempty"`
+	AnsibleFactsModified string            `json:"ansible_facts_modified,omitempty"`
+	AnsibleFqdn          string            `json:"ansible_fqdn,omitempty"`
+	InventorySource      string            `json:"inventory_source,omitempty"`
+	SummaryFields        HostSummaryFields `json:"summary_fields,omitempty"`
+}
+
+type HostSummaryFields struct {
+	Groups HostGroups `json:"groups"`
+}
+
+// HostGroups lists the groups a host is a direct member of, AWX/Tower only
+// returns the first few groups so the list is complete when `Count` matches.
+type HostGroups struct {
+	Count   int      `json:"count"`
+	Results []Object `json:"results"`
+}
+
+func (g HostGroups) IsComplete() bool {
+	return g.Count == len(g.Results)
 }
 
 func (i Host) MarshalJSON() ([]byte, error) {
@@ -82,21 +168,46 @@ func (h *Host) ToBHNode() (n *node.Node) {
 	props.SetProperty("inventory", strconv.FormatInt(int64(h.Inventory), 10))
 	props.SetProperty("enabled", strconv.FormatBool(h.Enabled))
 	props.SetProperty("instance_id", h.InstanceId)
-	props.SetProperty("variables", h.Variables)
+	Secrets.SetVariablesProperty(props, "variables", h.Variables)
+	setConnectionProperties(props, h.Variables)
+	setVaultProperties(props, h.Variables)
 	props.SetProperty("has_active_failures", strconv.FormatBool(h.HasActiveFailures))
 	props.SetProperty("last_job", strconv.FormatInt(int64(h.LastJob), 10))
 	props.SetProperty("last_job_host_summary", strconv.FormatInt(int64(h.LastJobHostSummary), 10))
 	props.SetProperty("ansible_facts_modified", h.AnsibleFactsModified)
+	props.SetProperty("ansible_fqdn", h.AnsibleFqdn)
+	props.SetProperty("inventory_source", h.InventorySource)
 	n, _ = node.NewNode(h.OID, []string{"ATHost"}, props)
 
 	return n
 }
 
+// Returns the names the host is known by, ordered from the most to the least reliable:
+// the `ansible_fqdn` fact, the `ansible_host` variable and the name of the host.
+func (h *Host) HostNames() (hostNames []string) {
+	candidates := []string{h.AnsibleFqdn}
+	if ansibleHost, ok := ParseVariables(h.Variables)[ANSIBLE_HOST_VARIABLE].(string); ok {
+		candidates = append(candidates, ansibleHost)
+	}
+	candidates = append(candidates, h.Name)
+
+	for _, candidate := range candidates {
+		candidate = strings.TrimSuffix(strings.TrimSpace(candidate), ".")
+		if candidate == "" || IsIPAddress(candidate) || slices.Contains(hostNames, strings.ToLower(candidate)) {
+			continue
+		}
+		hostNames = append(hostNames, strings.ToLower(candidate))
+	}
+	return hostNames
+}
+
 type Group struct {
 	Object
-	Inventory int           `json:"inventory,omitempty"`
-	Variables string        `json:"variables,omitempty"`
-	Hosts     map[int]*Host `json:"hosts,omitempty"`
+	Inventory int            `json:"inventory,omitempty"`
+	Variables string         `json:"variables,omitempty"`
+	Hosts     map[int]*Host  `json:"hosts,omitempty"`
+	Children  map[int]*Group `json:"children,omitempty"`
+	AllHosts  int            `json:"all_hosts,omitempty"`
 }
 
 func (i Group) MarshalJSON() ([]byte, error) {
@@ -114,8 +225,35 @@ func (g *Group) ToBHNode() (n *node.Node) {
 	props.SetProperty("created", g.Created)
 	props.SetProperty("modified", g.Modified)
 	props.SetProperty("inventory", strconv.FormatInt(int64(g.Inventory), 10))
-	props.SetProperty("variables", g.Variables)
+	Secrets.SetVariablesProperty(props, "variables", g.Variables)
+	setConnectionProperties(props, g.Variables)
+	setVaultProperties(props, g.Variables)
+	props.SetProperty("all_hosts", strconv.Itoa(g.AllHosts))
 	n, _ = node.NewNode(g.OID, []string{"ATGroup"}, props)
 
 	return n
 }
+
+// Returns the hosts of the group and of all its descendants, children are
+// looked up in `groups` since they are gathered without their hosts.
+func (g *Group) DescendantHosts(groups map[int]*Group) (hosts map[int]*Host) {
+	hosts = make(map[int]*Host)
+	visited := make(map[int]bool)
+	var walk func(group *Group)
+	walk = func(group *Group) {
+		if visited[group.ID] {
+			return
+		}
+		visited[group.ID] = true
+		for id, host := range group.Hosts {
+			hosts[id] = host
+		}
+		for childID := range group.Children {
+			if child, ok := groups[childID]; ok {
+				walk(child)
+			}
+		}
+	}
+	walk(g)
+	return hosts
+}
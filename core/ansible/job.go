@@ -3,6 +3,7 @@ package ansible
 import (
 	"encoding/json"
 	"strconv"
+	"strings"
 
 	"github.com/Ramoreik/gopengraph/node"
 	"github.com/Ramoreik/gopengraph/properties"
@@ -10,49 +11,52 @@ import (
 
 type Job struct {
 	Object
-	Inventory             int            `json:"inventory"`
-	Project               int            `json:"project"`
-	Organization          int            `json:"organization,omitempty"`
-	Playbook              string         `json:"playbook"`
-	ScmBranch             string         `json:"scm_branch,omitempty"`
-	Forks                 int            `json:"forks,omitempty"`
-	Limit                 string         `json:"limit,omitempty"`
-	Verbosity             int            `json:"verbosity,omitempty"`
-	ExtraVars             string         `json:"extra_vars,omitempty"`
-	Started               string         `json:"started,omitempty"`
-	Finished              string         `json:"finished,omitempty"`
-	CanceledOn            string         `json:"canceled_on,omitempty"`
-	Elapsed               float32        `json:"elapsed,omitempty"`
-	JobExplanation        string         `json:"job_explanation,omitempty"`
-	Created               string         `json:"created,omitempty"`
-	Modified              string         `json:"modified,omitempty"`
-	UnifiedJobTemplate    int            `json:"unified_job_template"`
-	LaunchType            string         `json:"launch_type"`
-	Failed                bool           `json:"failed"`
-	Status                string         `json:"status,omitempty"`
-	ExecutionEnvironment  int            `json:"execution_environment,omitempty"`
-	ExecutionNode         string         `json:"execution_node,omitempty"`
-	ControllerNode        string         `json:"controller_node,omitempty"`
-	LaunchedBy            map[string]any `json:"launched_by,omitempty"`
-	WorkUnitId            string         `json:"work_unit_id,omitempty"`
-	JobTags               string         `json:"job_tags,omitempty"`
-	JobType               string         `json:"job_type,omitempty"`
-	ForceHandler          bool           `json:"force_handlers,omitempty"`
-	SkipTags              string         `json:"skip_tags,omitempty"`
-	StartAtTask           string         `json:"start_at_task,omitempty"`
-	Timeout               int            `json:"timeout,omitempty"`
-	UseFactCache          bool           `json:"use_fact_cache,omitempty"`
-	PasswordNeededToStart string         `json:"password_needed_to_start,omitempty"`
-	AllowSimultaneous     bool           `json:"allow_simultaneous,omitempty"`
-	Artifacts             map[string]any `json:"artifacts,omitempty"`
-	ScmRevision           string         `json:"scm_revision,omitempty"`
-	InstanceGroup         int            `json:"instance_group,omitempty"`
-	DiffMode              bool           `json:"diff_mode,omitempty"`
-	JobSliceNumber        int            `json:"job_slice_number,omitempty"`
-	JobSliceCount         int            `json:"job_slice_count,omitempty"`
-	WebhookGuid           string         `json:"webhook_guid,omitempty"`
-	WebhookService        string         `json:"webhook_service,omitempty"`
-	WebhookCredential     int            `json:"webhook_credential,omitempty"`
+	Inventory             int             `json:"inventory"`
+	Project               int             `json:"project"`
+	Organization          int             `json:"organization,omitempty"`
+	Playbook              string          `json:"playbook"`
+	ScmBranch             string          `json:"scm_branch,omitempty"`
+	Forks                 int             `json:"forks,omitempty"`
+	Limit                 string          `json:"limit,omitempty"`
+	Verbosity             int             `json:"verbosity,omitempty"`
+	ExtraVars             string          `json:"extra_vars,omitempty"`
+	Started               string          `json:"started,omitempty"`
+	Finished              string          `json:"finished,omitempty"`
+	CanceledOn            string          `json:"canceled_on,omitempty"`
+	Elapsed               float32         `json:"elapsed,omitempty"`
+	JobExplanation        string          `json:"job_explanation,omitempty"`
+	Created               string          `json:"created,omitempty"`
+	Modified              string          `json:"modified,omitempty"`
+	UnifiedJobTemplate    int             `json:"unified_job_template"`
+	LaunchType            string          `json:"launch_type"`
+	Failed                bool            `json:"failed"`
+	Status                string          `json:"status,omitempty"`
+	ExecutionEnvironment  int             `json:"execution_environment,omitempty"`
+	ExecutionNode         string          `json:"execution_node,omitempty"`
+	ControllerNode        string          `json:"controller_node,omitempty"`
+	LaunchedBy            map[string]any  `json:"launched_by,omitempty"`
+	WorkUnitId            string          `json:"work_unit_id,omitempty"`
+	JobTags               string          `json:"job_tags,omitempty"`
+	JobType               string          `json:"job_type,omitempty"`
+	ForceHandler          bool            `json:"force_handlers,omitempty"`
+	SkipTags              string          `json:"skip_tags,omitempty"`
+	StartAtTask           string          `json:"start_at_task,omitempty"`
+	Timeout               int             `json:"timeout,omitempty"`
+	UseFactCache          bool            `json:"use_fact_cache,omitempty"`
+	PasswordNeededToStart string          `json:"password_needed_to_start,omitempty"`
+	AllowSimultaneous     bool            `json:"allow_simultaneous,omitempty"`
+	Artifacts             map[string]any  `json:"artifacts,omitempty"`
+	ScmRevision           string          `json:"scm_revision,omitempty"`
+	InstanceGroup         int             `json:"instance_group,omitempty"`
+	DiffMode              bool            `json:"diff_mode,omitempty"`
+	JobSliceNumber        int             `json:"job_slice_number,omitempty"`
+	JobSliceCount         int             `json:"job_slice_count,omitempty"`
+	WebhookGuid           string          `json:"webhook_guid,omitempty"`
+	WebhookService        string          `json:"webhook_service,omitempty"`
+	WebhookCredential     int             `json:"webhook_credential,omitempty"`
+	OutputScanned         bool            `json:"-"`
+	OutputTruncated       bool            `json:"-"`
+	OutputFindings        []SecretFinding `json:"-"`
 }
 
 func (j Job) MarshalJSON() ([]byte, error) {
@@ -74,7 +78,13 @@ func (j *Job) ToBHNode() (n *node.Node) {
 	props.SetProperty("forks", strconv.FormatInt(int64(j.Forks), 10))
 	props.SetProperty("limit", j.Limit)
 	props.SetProperty("verbosity", strconv.FormatInt(int64(j.Verbosity), 10))
-	props.SetProperty("extra_vars", j.ExtraVars)
+	findings := Secrets.SetVariablesProperty(props, "extra_vars", j.ExtraVars)
+	artifactsFindings := Secrets.ScanArtifacts(j.Artifacts)
+	props.SetProperty("artifacts_secret_keys", strings.Join(SecretKeys(artifactsFindings), ", "))
+	props.SetProperty("output_scanned", strconv.FormatBool(j.OutputScanned))
+	props.SetProperty("output_truncated", strconv.FormatBool(j.OutputTruncated))
+	props.SetProperty("output_secret_lines", strings.Join(SecretKeys(j.OutputFindings), ", "))
+	props.SetProperty("has_secret", strconv.FormatBool(len(findings)+len(artifactsFindings)+len(j.OutputFindings) > 0))
 	props.SetProperty("started", j.Started)
 	props.SetProperty("finished", j.Finished)
 	props.SetProperty("canceled_on", j.CanceledOn)
@@ -57,6 +57,7 @@ type JobTemplate struct {
 	WebhookService                  string              `json:"webhook_service,omitempty"`
 	WebhookCredential               int                 `json:"webhook_credential,omitempty"`
 	PreventInstanceGroupFallback    bool                `json:"prevent_instance_group_fallback,omitempty"`
+	PlaybookAnalysis                *PlaybookAnalysis   `json:"-"`
 }
 
 func (j JobTemplate) MarshalJSON() ([]byte, error) {
@@ -77,7 +78,7 @@ func (j *JobTemplate) ToBHNode() (n *node.Node) {
 	props.SetProperty("scm_branch", j.SCMBranch)
 	props.SetProperty("limit", j.Limit)
 	props.SetProperty("verbosity", strconv.FormatInt(int64(j.Verbosity), 10))
-	props.SetProperty("extra_vars", j.ExtraVars)
+	Secrets.SetVariablesProperty(props, "extra_vars", j.ExtraVars)
 	props.SetProperty("status", j.Status)
 	props.SetProperty("job_tags", j.JobTags)
 	props.SetProperty("forks", strconv.FormatInt(int64(j.Forks), 10))
@@ -115,6 +116,12 @@ func (j *JobTemplate) ToBHNode() (n *node.Node) {
 	props.SetProperty("webhook_service", j.WebhookService)
 	props.SetProperty("webhook_credential", strconv.FormatInt(int64(j.WebhookCredential), 10))
 	props.SetProperty("prevent_instance_group_fallback", strconv.FormatBool(j.PreventInstanceGroupFallback))
+	props.SetProperty("playbook_analyzed", strconv.FormatBool(j.PlaybookAnalysis != nil))
+	if j.PlaybookAnalysis != nil {
+		for key, value := range j.PlaybookAnalysis.Properties() {
+			props.SetProperty(key, value)
+		}
+	}
 	n, _ = node.NewNode(j.OID, []string{"ATJobTemplate"}, props)
 
 	return n
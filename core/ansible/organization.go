@@ -10,9 +10,10 @@ import (
 
 type Organization struct {
 	Object
-	MaxHosts           int    `json:"max_hosts,omitempty"`
-	CustomVirtualenv   string `json:"custom_virtualenv,omitempty"`
-	DefaultEnvironment int    `json:"default_environment,omitempty"`
+	MaxHosts           int           `json:"max_hosts,omitempty"`
+	CustomVirtualenv   string        `json:"custom_virtualenv,omitempty"`
+	DefaultEnvironment int           `json:"default_environment,omitempty"`
+	GalaxyCredentials  []*Credential `json:"galaxy_credentials"`
 }
 
 func (o Organization) MarshalJSON() ([]byte, error) {
@@ -0,0 +1,232 @@
+package ansible
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+const ALL_GROUP = "all"
+const UNGROUPED_GROUP = "ungrouped"
+
+// Matches the terms of a pattern separated by colons, brackets (EX: subscripts) are kept whole.
+var hostPatternTermPattern = regexp.MustCompile(`(?:[^\s:\[\]]|\[[^\]]*\])+`)
+
+// Matches `[host]:port` and `host:port` addresses, colons of ranges are ignored (EX: `web[01:10]:2222`).
+var bracketedHostPortPattern = regexp.MustCompile(`^\[(.+)\]:([0-9]+)$`)
+var hostPortPattern = regexp.MustCompile(`^((?:[^:\[\]]|\[[^\]]*\])*):([0-9]+)$`)
+
+// Matches host names made of labels with alphanumeric ranges (EX: `web[01:10].corp`),
+// labels cannot end with `-` or `_`.
+var hostNamePattern = regexp.MustCompile(`(?i)^` + hostLabel + `(?:\.` + hostLabel + `)*$`)
+
+const hostRange = `\[(?:[a-z]:[a-z]|[0-9]+:[0-9]+)(?::[0-9]+)?\]`
+const hostLabel = `(?:(?:\w|` + hostRange + `)(?:[\w-]|` + hostRange + `)*)?(?:[a-z0-9]|` + hostRange + `)`
+
+// Matches subscripts (EX: `webservers[0]`, `webservers[0:2]`), which select hosts by position.
+var hostPatternSubscriptPattern = regexp.MustCompile(`^(.+)\[(?:-?[0-9]+|[0-9]*[:-][0-9]*)\]$`)
+
+// HostScope is the set of hosts and groups a host pattern is evaluated against (EX: an inventory).
+type HostScope struct {
+	Hosts      map[int]*Host
+	Groups     map[int]*Group
+	groupHosts map[int]map[int]*Host
+	grouped    map[int]bool
+}
+
+func NewHostScope(hosts map[int]*Host, groups map[int]*Group) (scope *HostScope) {
+	scope = &HostScope{
+		Hosts:      hosts,
+		Groups:     groups,
+		groupHosts: make(map[int]map[int]*Host),
+		grouped:    make(map[int]bool),
+	}
+	for id, group := range groups {
+		scope.groupHosts[id] = group.DescendantHosts(groups)
+		for hostID := range scope.groupHosts[id] {
+			scope.grouped[hostID] = true
+		}
+	}
+	return scope
+}
+
+// Splits a host pattern into its terms, patterns are separated by commas or,
+// in older playbooks, by colons. Like Ansible, a pattern without commas is kept
+// whole when it is a host address (EX: `fe80::1`, `web1:2222`).
+func SplitHostPattern(pattern string) (terms []string) {
+	if strings.Contains(pattern, ",") {
+		for _, term := range strings.Split(pattern, ",") {
+			if term = strings.TrimSpace(term); term != "" {
+				terms = append(terms, term)
+			}
+		}
+		return terms
+	}
+	pattern = strings.TrimSpace(pattern)
+	if isHostAddress(pattern) {
+		return []string{pattern}
+	}
+	return hostPatternTermPattern.FindAllString(pattern, -1)
+}
+
+// Returns true when the pattern is an IP address or a host name, optionally followed
+// by a port, following the `parse_address` rules of Ansible.
+func isHostAddress(pattern string) bool {
+	host := pattern
+	if match := bracketedHostPortPattern.FindStringSubmatch(host); match != nil {
+		host = match[1]
+	}
+	if match := hostPortPattern.FindStringSubmatch(host); match != nil {
+		host = match[1]
+	}
+	return net.ParseIP(host) != nil || hostNamePattern.MatchString(host)
+}
+
+// Returns the hosts selected by an Ansible host pattern, evaluated like Ansible does: unions
+// first, then intersections (`&`) and exclusions (`!`). Terms are group names, host names,
+// wildcards or regular expressions (`~`). Subscripts cannot be evaluated since the order of
+// the hosts is unknown, the whole group is kept and `exact` is false.
+func (s *HostScope) MatchHostPattern(pattern string) (matched map[int]*Host, exact bool, err error) {
+
+	var unions, intersections, exclusions []string
+	for _, term := range SplitHostPattern(pattern) {
+		switch term[0] {
+		case '&':
+			intersections = append(intersections, term[1:])
+		case '!':
+			exclusions = append(exclusions, term[1:])
+		default:
+			unions = append(unions, term)
+		}
+	}
+	if len(unions) == 0 {
+		unions = []string{ALL_GROUP}
+	}
+
+	exact = true
+	evaluate := func(term string) map[int]*Host {
+		termHosts, termExact, termErr := s.matchTerm(term)
+		exact = exact && termExact
+		if termErr != nil && err == nil {
+			err = termErr
+		}
+		return termHosts
+	}
+
+	matched = make(map[int]*Host)
+	for _, term := range unions {
+		for id, host := range evaluate(term) {
+			matched[id] = host
+		}
+	}
+	for _, term := range intersections {
+		termHosts := evaluate(term)
+		for id := range matched {
+			if _, ok := termHosts[id]; !ok {
+				delete(matched, id)
+			}
+		}
+	}
+	for _, term := range exclusions {
+		for id := range evaluate(term) {
+			delete(matched, id)
+		}
+	}
+
+	return matched, exact, err
+}
+
+func (s *HostScope) matchTerm(term string) (matched map[int]*Host, exact bool, err error) {
+
+	matched = make(map[int]*Host)
+	exact = true
+
+	if !strings.HasPrefix(term, "~") {
+		if subscript := hostPatternSubscriptPattern.FindStringSubmatch(term); subscript != nil {
+			term = subscript[1]
+			exact = false
+		}
+	}
+
+	var match func(name string) bool
+	if regex, ok := strings.CutPrefix(term, "~"); ok {
+		// NOTE: Ansible uses `re.match`, which is only anchored at the start.
+		compiled, err := regexp.Compile("^(?:" + regex + ")")
+		if err != nil {
+			return matched, false, fmt.Errorf("invalid host pattern `%s`: %w", term, err)
+		}
+		match = compiled.MatchString
+	} else {
+		match = globPattern(term).MatchString
+	}
+
+	matchedGroup := false
+	for id, group := range s.Groups {
+		if !match(group.Name) {
+			continue
+		}
+		matchedGroup = true
+		for hostID := range s.groupHosts[id] {
+			if host, ok := s.Hosts[hostID]; ok {
+				matched[hostID] = host
+			}
+		}
+	}
+
+	// NOTE: `all` and `ungrouped` are implicit groups of every inventory.
+	matchAll, matchUngrouped := match(ALL_GROUP), match(UNGROUPED_GROUP)
+	if matchAll || matchUngrouped {
+		matchedGroup = true
+		for id, host := range s.Hosts {
+			if matchAll || !s.grouped[id] {
+				matched[id] = host
+			}
+		}
+	}
+
+	// NOTE: Host names are only considered when no group matched or when the term is a wildcard or regex.
+	if !matchedGroup || strings.HasPrefix(term, "~") || strings.ContainsAny(term, ".?*[") {
+		for id, host := range s.Hosts {
+			if match(host.Name) {
+				matched[id] = host
+			}
+		}
+	}
+
+	return matched, exact, nil
+}
+
+// Translates a shell wildcard (`*`, `?`, `[...]`) into an anchored regular expression.
+func globPattern(glob string) *regexp.Regexp {
+	var expression strings.Builder
+	expression.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch glob[i] {
+		case '*':
+			expression.WriteString(".*")
+		case '?':
+			expression.WriteString(".")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				expression.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if negated, ok := strings.CutPrefix(class, "!"); ok {
+				class = "^" + negated
+			}
+			expression.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		default:
+			expression.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		}
+	}
+	expression.WriteString("$")
+	compiled, err := regexp.Compile(expression.String())
+	if err != nil {
+		return regexp.MustCompile("^" + regexp.QuoteMeta(glob) + "$")
+	}
+	return compiled
+}
@@ -0,0 +1,130 @@
+package ansible
+
+import (
+	"slices"
+	"testing"
+)
+
+// Inventory used by the pattern tests:
+//
+//	web:  web1.corp, web2.corp
+//	db:   db1.corp
+//	prod: children web, db
+//	ungrouped: bastion
+func testHostScope() *HostScope {
+	hosts := make(map[int]*Host)
+	for id, name := range []string{"web1.corp", "web2.corp", "db1.corp", "bastion"} {
+		hosts[id+1] = &Host{Object: Object{ID: id + 1, Name: name}}
+	}
+	web := &Group{Object: Object{ID: 1, Name: "web"}, Hosts: map[int]*Host{1: hosts[1], 2: hosts[2]}}
+	db := &Group{Object: Object{ID: 2, Name: "db"}, Hosts: map[int]*Host{3: hosts[3]}}
+	prod := &Group{Object: Object{ID: 3, Name: "prod"}, Children: map[int]*Group{1: web, 2: db}}
+	return NewHostScope(hosts, map[int]*Group{1: web, 2: db, 3: prod})
+}
+
+func hostNames(hosts map[int]*Host) (names []string) {
+	for _, host := range hosts {
+		names = append(names, host.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestSplitHostPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		terms   []string
+	}{
+		{"web", []string{"web"}},
+		{"web,db", []string{"web", "db"}},
+		{" web , &prod ,!db ", []string{"web", "&prod", "!db"}},
+		{"web:db:&prod", []string{"web", "db", "&prod"}},
+		{"web[0:2]:db", []string{"web[0:2]", "db"}},
+		{"~web[0-9]+:db", []string{"~web[0-9]+", "db"}},
+		{"fe80::1,db", []string{"fe80::1", "db"}},
+		{"fe80::1", []string{"fe80::1"}},
+		{"[fe80::1]:2222", []string{"[fe80::1]:2222"}},
+		{"web1:2222", []string{"web1:2222"}},
+		{"10.0.0.1:22", []string{"10.0.0.1:22"}},
+		{"web[01:10].corp", []string{"web[01:10].corp"}},
+		{"web-:db", []string{"web-", "db"}},
+	}
+	for _, test := range tests {
+		terms := SplitHostPattern(test.pattern)
+		if !slices.Equal(terms, test.terms) {
+			t.Errorf("SplitHostPattern(%q) = %q, expected %q", test.pattern, terms, test.terms)
+		}
+	}
+}
+
+func TestMatchHostPattern(t *testing.T) {
+	scope := testHostScope()
+	tests := []struct {
+		pattern string
+		hosts   []string
+		exact   bool
+	}{
+		{"all", []string{"bastion", "db1.corp", "web1.corp", "web2.corp"}, true},
+		{"*", []string{"bastion", "db1.corp", "web1.corp", "web2.corp"}, true},
+		{"ungrouped", []string{"bastion"}, true},
+		{"web", []string{"web1.corp", "web2.corp"}, true},
+		{"prod", []string{"db1.corp", "web1.corp", "web2.corp"}, true},
+		{"web,db", []string{"db1.corp", "web1.corp", "web2.corp"}, true},
+		{"web:db", []string{"db1.corp", "web1.corp", "web2.corp"}, true},
+		{"prod,!db", []string{"web1.corp", "web2.corp"}, true},
+		{"all:&web", []string{"web1.corp", "web2.corp"}, true},
+		{"web1.corp", []string{"web1.corp"}, true},
+		{"web*.corp", []string{"web1.corp", "web2.corp"}, true},
+		{"web[12].corp", []string{"web1.corp", "web2.corp"}, true},
+		{"web[!1].corp", []string{"web2.corp"}, true},
+		{"~web[0-9]", []string{"web1.corp", "web2.corp"}, true},
+		{"~corp", nil, true},
+		{"web[0]", []string{"web1.corp", "web2.corp"}, false},
+		{"web[0:1],db", []string{"db1.corp", "web1.corp", "web2.corp"}, false},
+		{"missing", nil, true},
+		{"&web", []string{"web1.corp", "web2.corp"}, true},
+	}
+	for _, test := range tests {
+		matched, exact, err := scope.MatchHostPattern(test.pattern)
+		if err != nil {
+			t.Errorf("MatchHostPattern(%q) failed: %v", test.pattern, err)
+			continue
+		}
+		if names := hostNames(matched); !slices.Equal(names, test.hosts) {
+			t.Errorf("MatchHostPattern(%q) = %q, expected %q", test.pattern, names, test.hosts)
+		}
+		if exact != test.exact {
+			t.Errorf("MatchHostPattern(%q) exact = %t, expected %t", test.pattern, exact, test.exact)
+		}
+	}
+}
+
+func TestMatchHostPatternInvalidRegex(t *testing.T) {
+	scope := testHostScope()
+	if _, exact, err := scope.MatchHostPattern("~web(["); err == nil || exact {
+		t.Errorf("MatchHostPattern(%q) expected an error and an inexact match, got exact = %t, err = %v", "~web([", exact, err)
+	}
+}
+
+func TestGlobPattern(t *testing.T) {
+	tests := []struct {
+		glob    string
+		name    string
+		matches bool
+	}{
+		{"web*", "web1.corp", true},
+		{"web*", "db1", false},
+		{"web?", "web1", true},
+		{"web?", "web12", false},
+		{"web.corp", "webxcorp", false},
+		{"web[0-9]", "web7", true},
+		{"web[!0-9]", "web7", false},
+		{"web[", "web[", true},
+		{"web[z-a]", "web[z-a]", true},
+	}
+	for _, test := range tests {
+		if matches := globPattern(test.glob).MatchString(test.name); matches != test.matches {
+			t.Errorf("globPattern(%q).MatchString(%q) = %t, expected %t", test.glob, test.name, matches, test.matches)
+		}
+	}
+}
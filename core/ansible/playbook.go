@@ -0,0 +1,105 @@
+package ansible
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Lookup plugins retrieving secrets, matched on the last component of their name
+// (EX: `community.hashi_vault.hashi_vault`).
+var SECRET_LOOKUP_PLUGINS = []string{
+	"hashi_vault", "vault_kv1_get", "vault_kv2_get", "vault_read",
+	"aws_secret", "secretsmanager_secret", "aws_ssm", "ssm_parameter",
+	"azure_keyvault_secret", "cyberarkpassword", "conjur_variable", "env",
+}
+
+const ENV_LOOKUP_PLUGIN = "env"
+
+// Matches `lookup('plugin', 'term')`, `query(...)` and `q(...)` calls in Jinja expressions.
+var lookupPattern = regexp.MustCompile(`\b(?:lookup|query|q)\(\s*['"]([A-Za-z0-9_.]+)['"]\s*(?:,\s*['"]([^'"]*)['"])?`)
+
+// PlaybookAnalysis is what a playbook, and the playbooks, tasks and roles it imports, does
+// according to a checkout of its project.
+type PlaybookAnalysis struct {
+	Hosts       []string
+	Become      bool
+	BecomeUsers []string
+	DelegateTo  []string
+	Lookups     []string
+	EnvLookups  []string
+	Roles       []string
+	Files       []string
+}
+
+func addUnique(values []string, value string) []string {
+	if value == "" || slices.Contains(values, value) {
+		return values
+	}
+	return append(values, value)
+}
+
+// Records the secret lookups of a Jinja expression, the variable names of `env` lookups are kept.
+func (a *PlaybookAnalysis) ScanLookups(expression string) {
+	for _, match := range lookupPattern.FindAllStringSubmatch(expression, -1) {
+		plugin := match[1][strings.LastIndex(match[1], ".")+1:]
+		if !slices.Contains(SECRET_LOOKUP_PLUGINS, plugin) {
+			continue
+		}
+		a.Lookups = addUnique(a.Lookups, plugin)
+		if plugin == ENV_LOOKUP_PLUGIN {
+			a.EnvLookups = addUnique(a.EnvLookups, match[2])
+		}
+	}
+}
+
+func (a *PlaybookAnalysis) AddHosts(pattern string) {
+	a.Hosts = addUnique(a.Hosts, strings.TrimSpace(pattern))
+}
+
+func (a *PlaybookAnalysis) AddBecomeUser(user string) {
+	a.BecomeUsers = addUnique(a.BecomeUsers, user)
+}
+
+func (a *PlaybookAnalysis) AddDelegateTo(target string) {
+	a.DelegateTo = addUnique(a.DelegateTo, target)
+}
+
+func (a *PlaybookAnalysis) AddRole(role string) {
+	a.Roles = addUnique(a.Roles, role)
+}
+
+// Returns true when the play hosts can be evaluated, templated patterns (EX: `{{ target }}`)
+// depend on variables only known at runtime.
+func (a *PlaybookAnalysis) HostsResolvable() bool {
+	return !slices.ContainsFunc(a.Hosts, func(pattern string) bool {
+		return strings.Contains(pattern, "{{")
+	})
+}
+
+func (a *PlaybookAnalysis) Properties() map[string]string {
+	return map[string]string{
+		"playbook_hosts":        strings.Join(a.Hosts, "; "),
+		"playbook_become":       strconv.FormatBool(a.Become),
+		"playbook_become_users": strings.Join(a.BecomeUsers, ", "),
+		"playbook_delegate_to":  strings.Join(a.DelegateTo, ", "),
+		"playbook_lookups":      strings.Join(a.Lookups, ", "),
+		"playbook_env_lookups":  strings.Join(a.EnvLookups, ", "),
+		"playbook_roles":        strings.Join(a.Roles, ", "),
+		"playbook_files":        strconv.Itoa(len(a.Files)),
+	}
+}
+
+// Returns true for the truthy values of YAML and Ansible booleans (EX: `yes`, `True`).
+func IsTruthy(value any) bool {
+	switch typed := value.(type) {
+	case bool:
+		return typed
+	case string:
+		return slices.Contains([]string{"yes", "y", "true", "on", "1"}, strings.ToLower(strings.TrimSpace(typed)))
+	case int:
+		return typed != 0
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package ansible
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestScanLookups(t *testing.T) {
+	tests := []struct {
+		expression string
+		lookups    []string
+		envLookups []string
+	}{
+		{"{{ lookup('community.hashi_vault.hashi_vault', 'secret/db') }}", []string{"hashi_vault"}, nil},
+		{`{{ query("amazon.aws.aws_secret", "db") }}`, []string{"aws_secret"}, nil},
+		{"{{ q('env', 'HOME') }} {{ lookup('ansible.builtin.env', 'TOKEN') }}", []string{"env"}, []string{"HOME", "TOKEN"}},
+		{"{{ lookup('file', '/etc/motd') }}", nil, nil},
+		{"{{ mylookup('hashi_vault', 'secret') }}", nil, nil},
+		{"lookup without call", nil, nil},
+	}
+	for _, test := range tests {
+		analysis := &PlaybookAnalysis{}
+		analysis.ScanLookups(test.expression)
+		if !slices.Equal(analysis.Lookups, test.lookups) || !slices.Equal(analysis.EnvLookups, test.envLookups) {
+			t.Errorf("ScanLookups(%q) = (%q, %q), expected (%q, %q)", test.expression,
+				analysis.Lookups, analysis.EnvLookups, test.lookups, test.envLookups)
+		}
+	}
+}
+
+func TestHostsResolvable(t *testing.T) {
+	tests := []struct {
+		hosts      []string
+		resolvable bool
+	}{
+		{nil, true},
+		{[]string{"web", "db:!decommissioned"}, true},
+		{[]string{"web", "{{ target }}"}, false},
+	}
+	for _, test := range tests {
+		analysis := &PlaybookAnalysis{}
+		for _, pattern := range test.hosts {
+			analysis.AddHosts(pattern)
+		}
+		if resolvable := analysis.HostsResolvable(); resolvable != test.resolvable {
+			t.Errorf("HostsResolvable(%q) = %t, expected %t", test.hosts, resolvable, test.resolvable)
+		}
+	}
+}
+
+func TestIsTruthy(t *testing.T) {
+	tests := []struct {
+		value  any
+		truthy bool
+	}{
+		{true, true},
+		{false, false},
+		{"yes", true},
+		{" True ", true},
+		{"on", true},
+		{"no", false},
+		{"{{ become }}", false},
+		{1, true},
+		{0, false},
+		{nil, false},
+	}
+	for _, test := range tests {
+		if truthy := IsTruthy(test.value); truthy != test.truthy {
+			t.Errorf("IsTruthy(%#v) = %t, expected %t", test.value, truthy, test.truthy)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package ansible
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Ramoreik/gopengraph/properties"
+)
+
+const REDACTED_VALUE = "<redacted>"
+
+// SecretRule flags a value either because of the name of its key or because of its content.
+type SecretRule struct {
+	Name      string
+	Key       *regexp.Regexp
+	Value     *regexp.Regexp
+	Multiline bool
+}
+
+var DEFAULT_SECRET_RULES = []SecretRule{
+	{Name: "ansible_connection_password", Key: regexp.MustCompile(`(?i)^ansible_(ssh_|become_|sudo_|su_|winrm_|httpapi_|net_|)pass(word)?$`)},
+	{Name: "ansible_private_key", Key: regexp.MustCompile(`(?i)^ansible_(ssh_)?private_key$`)},
+	{Name: "generic_secret_key", Key: regexp.MustCompile(`(?i)(^|_)(password|passwd|pwd|secret|token|api_?key|private_?key|client_secret)$`)},
+	{Name: "pem_private_key", Value: regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY-----(?:[\s\S]*?-----END (?:[A-Z0-9]+ )*PRIVATE KEY-----)?`), Multiline: true},
+	{Name: "aws_access_key_id", Value: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{Name: "github_token", Value: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{Name: "gitlab_token", Value: regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`)},
+	{Name: "slack_token", Value: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}`)},
+	{Name: "jwt", Value: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+}
+
+// Matches `key: value` and `key=value` pairs in free text (EX: job output).
+var keyValuePattern = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_]*)["']?\s*[:=]\s*["']?([^\s"',;}]+)`)
+
+// SecretDetection holds the rules applied to variables and whether matched values are kept.
+type SecretDetection struct {
+	Rules       []SecretRule
+	KeepSecrets bool
+}
+
+var Secrets = SecretDetection{Rules: DEFAULT_SECRET_RULES}
+
+// Adds a user supplied rule matching the content of values.
+func (s *SecretDetection) AddRule(name string, pattern string) error {
+	value, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid secret rule `%s`: %w", name, err)
+	}
+	s.Rules = append(s.Rules, SecretRule{Name: name, Value: value})
+	return nil
+}
+
+// SecretFinding is a secret found under a key, or on a line of free text.
+type SecretFinding struct {
+	Rule string
+	Key  string
+	Line int
+}
+
+// Returns true for values that hold no plaintext secret: empty values, Jinja
+// templates, vault encrypted values and values masked by AWX/Tower.
+func isPlaceholder(value string) bool {
+	value = strings.TrimSpace(value)
+	return value == "" || value == "$encrypted$" || IsVaultEncrypted(value) ||
+		strings.HasPrefix(value, "{{") && strings.HasSuffix(value, "}}")
+}
+
+func (s *SecretDetection) scanValue(path string, key string, value any, findings *[]SecretFinding) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		for childKey, childValue := range typed {
+			typed[childKey] = s.scanValue(joinPath(path, childKey), childKey, childValue, findings)
+		}
+		return typed
+	case []any:
+		for i, childValue := range typed {
+			typed[i] = s.scanValue(path+"["+strconv.Itoa(i)+"]", key, childValue, findings)
+		}
+		return typed
+	}
+
+	scalar := fmt.Sprint(value)
+	if value == nil || isPlaceholder(scalar) {
+		return value
+	}
+
+	for _, rule := range s.Rules {
+		if rule.Key != nil && rule.Key.MatchString(key) {
+			*findings = append(*findings, SecretFinding{Rule: rule.Name, Key: path})
+			return REDACTED_VALUE
+		}
+	}
+	redacted := scalar
+	for _, rule := range s.Rules {
+		if rule.Value != nil && rule.Value.MatchString(redacted) {
+			*findings = append(*findings, SecretFinding{Rule: rule.Name, Key: path})
+			redacted = rule.Value.ReplaceAllString(redacted, REDACTED_VALUE)
+		}
+	}
+	if redacted != scalar {
+		return redacted
+	}
+	return value
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// Scans free text line by line, the returned text has its secrets redacted.
+func (s *SecretDetection) ScanText(text string) (redacted string, findings []SecretFinding) {
+
+	// NOTE: Multiline secrets (EX: PEM blocks) are matched on the whole text first,
+	// newlines are kept so that the following lines keep their number.
+	for _, rule := range s.Rules {
+		if rule.Value == nil || !rule.Multiline {
+			continue
+		}
+		for _, match := range rule.Value.FindAllStringIndex(text, -1) {
+			findings = append(findings, SecretFinding{Rule: rule.Name, Line: strings.Count(text[:match[0]], "\n") + 1})
+		}
+		text = rule.Value.ReplaceAllStringFunc(text, func(match string) string {
+			return REDACTED_VALUE + strings.Repeat("\n", strings.Count(match, "\n"))
+		})
+	}
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		for _, match := range keyValuePattern.FindAllStringSubmatch(line, -1) {
+			key, value := match[1], match[2]
+			// NOTE: Values stop at the first space, Jinja templates are only matched by their start.
+			if isPlaceholder(value) || strings.HasPrefix(value, "{{") {
+				continue
+			}
+			for _, rule := range s.Rules {
+				if rule.Key != nil && rule.Key.MatchString(key) {
+					findings = append(findings, SecretFinding{Rule: rule.Name, Key: key, Line: i + 1})
+					lines[i] = strings.Replace(lines[i], value, REDACTED_VALUE, 1)
+					break
+				}
+			}
+		}
+		for _, rule := range s.Rules {
+			if rule.Value != nil && !rule.Multiline && rule.Value.MatchString(lines[i]) {
+				findings = append(findings, SecretFinding{Rule: rule.Name, Line: i + 1})
+				lines[i] = rule.Value.ReplaceAllString(lines[i], REDACTED_VALUE)
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n"), findings
+}
+
+// Scans variables (YAML or JSON), the returned variables have their secrets
+// redacted and are serialized as JSON when a secret was found.
+func (s *SecretDetection) ScanVariables(variables string) (redacted string, findings []SecretFinding) {
+
+	if strings.TrimSpace(variables) == "" {
+		return variables, nil
+	}
+
+	parsed := ParseVariables(variables)
+	if len(parsed) == 0 {
+		return s.ScanText(variables)
+	}
+
+	s.scanValue("", "", parsed, &findings)
+	if len(findings) == 0 {
+		return variables, nil
+	}
+
+	var content strings.Builder
+	encoder := json.NewEncoder(&content)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(parsed)
+	if err != nil {
+		return REDACTED_VALUE, findings
+	}
+	return strings.TrimSpace(content.String()), findings
+}
+
+// Returns the keys (or lines) secrets were found at, sorted and deduplicated.
+func SecretKeys(findings []SecretFinding) (keys []string) {
+	for _, finding := range findings {
+		key := finding.Key
+		if finding.Line != 0 {
+			key = fmt.Sprintf("%s (line %d)", finding.Rule, finding.Line)
+			if finding.Key != "" {
+				key = fmt.Sprintf("%s (line %d)", finding.Key, finding.Line)
+			}
+		}
+		if !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Sets the variables property of a node along with `has_secret` and `secret_keys`,
+// the variables are redacted unless secrets are kept.
+func (s *SecretDetection) SetVariablesProperty(props *properties.Properties, name string, variables string) []SecretFinding {
+	redacted, findings := s.ScanVariables(variables)
+	if s.KeepSecrets {
+		redacted = variables
+	}
+	props.SetProperty(name, redacted)
+	props.SetProperty("has_secret", strconv.FormatBool(len(findings) > 0))
+	props.SetProperty("secret_keys", strings.Join(SecretKeys(findings), ", "))
+	return findings
+}
+
+// Scans job artifacts, which are set by the `set_stats` module.
+func (s *SecretDetection) ScanArtifacts(artifacts map[string]any) []SecretFinding {
+	if len(artifacts) == 0 {
+		return nil
+	}
+	content, err := json.Marshal(artifacts)
+	if err != nil {
+		return nil
+	}
+	_, findings := s.ScanVariables(string(content))
+	return findings
+}
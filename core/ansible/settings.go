@@ -0,0 +1,279 @@
+package ansible
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Ramoreik/gopengraph/properties"
+)
+
+// AWX/Tower supports a default LDAP server and up to five additional ones,
+// each using its own prefix (`AUTH_LDAP_`, `AUTH_LDAP_1_`, ..., `AUTH_LDAP_5_`).
+var LdapServerIndexes = []string{"", "1", "2", "3", "4", "5"}
+
+type LdapSettings struct {
+	Index            string         `json:"-"`
+	ServerUri        string         `json:"SERVER_URI,omitempty"`
+	BindDn           string         `json:"BIND_DN,omitempty"`
+	BindPassword     string         `json:"BIND_PASSWORD,omitempty"`
+	StartTls         bool           `json:"START_TLS,omitempty"`
+	UserSearch       []any          `json:"USER_SEARCH,omitempty"`
+	UserDnTemplate   string         `json:"USER_DN_TEMPLATE,omitempty"`
+	GroupSearch      []any          `json:"GROUP_SEARCH,omitempty"`
+	GroupType        string         `json:"GROUP_TYPE,omitempty"`
+	RequireGroup     string         `json:"REQUIRE_GROUP,omitempty"`
+	DenyGroup        string         `json:"DENY_GROUP,omitempty"`
+	UserFlagsByGroup map[string]any `json:"USER_FLAGS_BY_GROUP,omitempty"`
+	OrganizationMap  map[string]any `json:"ORGANIZATION_MAP,omitempty"`
+	TeamMap          map[string]any `json:"TEAM_MAP,omitempty"`
+}
+
+// Prefix used for the node properties of this LDAP server (`ldap_` or `ldap_N_`).
+func (l *LdapSettings) PropertyPrefix() string {
+	if l.Index == "" {
+		return "ldap_"
+	}
+	return "ldap_" + l.Index + "_"
+}
+
+type LdapRoleGrant struct {
+	GroupDn      string
+	ResourceType string
+	ResourceName string
+	Organization string
+	Role         string
+	Remove       bool
+}
+
+// Values of LDAP maps can either be a DN, a list of DNs or a boolean (all or no users),
+// only DNs can be linked to Active Directory groups.
+func mapValueToDns(value any) (dns []string) {
+	switch value := value.(type) {
+	case string:
+		if value != "" {
+			dns = append(dns, value)
+		}
+	case []any:
+		for _, entry := range value {
+			if dn, ok := entry.(string); ok && dn != "" {
+				dns = append(dns, dn)
+			}
+		}
+	}
+	return dns
+}
+
+func (l *LdapSettings) RoleGrants() (grants []LdapRoleGrant) {
+
+	organizationRoles := map[string]string{
+		"admins":   "Admin",
+		"users":    "Member",
+		"auditors": "Auditor",
+	}
+
+	for organizationName, entry := range l.OrganizationMap {
+		entry, ok := entry.(map[string]any)
+		if !ok {
+			continue
+		}
+		for key, role := range organizationRoles {
+			remove, _ := entry["remove_"+key].(bool)
+			for _, dn := range mapValueToDns(entry[key]) {
+				grants = append(grants, LdapRoleGrant{
+					GroupDn:      dn,
+					ResourceType: "organization",
+					ResourceName: organizationName,
+					Role:         role,
+					Remove:       remove,
+				})
+			}
+		}
+	}
+
+	for teamName, entry := range l.TeamMap {
+		entry, ok := entry.(map[string]any)
+		if !ok {
+			continue
+		}
+		organizationName, _ := entry["organization"].(string)
+		remove, _ := entry["remove"].(bool)
+		for _, dn := range mapValueToDns(entry["users"]) {
+			grants = append(grants, LdapRoleGrant{
+				GroupDn:      dn,
+				ResourceType: "team",
+				ResourceName: teamName,
+				Organization: organizationName,
+				Role:         "Member",
+				Remove:       remove,
+			})
+		}
+	}
+
+	return grants
+}
+
+type Settings struct {
+	DisableLocalAuth            bool           `json:"DISABLE_LOCAL_AUTH,omitempty"`
+	AuthBasicEnabled            bool           `json:"AUTH_BASIC_ENABLED,omitempty"`
+	SessionCookieAge            int            `json:"SESSION_COOKIE_AGE,omitempty"`
+	SessionsPerUser             int            `json:"SESSIONS_PER_USER,omitempty"`
+	AllowOAuth2ForExternalUsers bool           `json:"ALLOW_OAUTH2_FOR_EXTERNAL_USERS,omitempty"`
+	OAuth2Provider              map[string]any `json:"OAUTH2_PROVIDER,omitempty"`
+	RemoteHostHeaders           []string       `json:"REMOTE_HOST_HEADERS,omitempty"`
+
+	SamlSpEntityId         string         `json:"SOCIAL_AUTH_SAML_SP_ENTITY_ID,omitempty"`
+	SamlSpPrivateKey       string         `json:"SOCIAL_AUTH_SAML_SP_PRIVATE_KEY,omitempty"`
+	SamlEnabledIdps        map[string]any `json:"SOCIAL_AUTH_SAML_ENABLED_IDPS,omitempty"`
+	SamlOrganizationMap    map[string]any `json:"SOCIAL_AUTH_SAML_ORGANIZATION_MAP,omitempty"`
+	SamlTeamMap            map[string]any `json:"SOCIAL_AUTH_SAML_TEAM_MAP,omitempty"`
+	SamlOrganizationAttr   map[string]any `json:"SOCIAL_AUTH_SAML_ORGANIZATION_ATTR,omitempty"`
+	SamlTeamAttr           map[string]any `json:"SOCIAL_AUTH_SAML_TEAM_ATTR,omitempty"`
+	SamlUserFlagsByAttr    map[string]any `json:"SOCIAL_AUTH_SAML_USER_FLAGS_BY_ATTR,omitempty"`
+	OidcKey                string         `json:"SOCIAL_AUTH_OIDC_KEY,omitempty"`
+	OidcSecret             string         `json:"SOCIAL_AUTH_OIDC_SECRET,omitempty"`
+	OidcEndpoint           string         `json:"SOCIAL_AUTH_OIDC_OIDC_ENDPOINT,omitempty"`
+	OidcVerifySSL          bool           `json:"SOCIAL_AUTH_OIDC_VERIFY_SSL,omitempty"`
+	AzureADOAuth2Key       string         `json:"SOCIAL_AUTH_AZUREAD_OAUTH2_KEY,omitempty"`
+	AzureADOAuth2Secret    string         `json:"SOCIAL_AUTH_AZUREAD_OAUTH2_SECRET,omitempty"`
+	AzureADOrganizationMap map[string]any `json:"SOCIAL_AUTH_AZUREAD_OAUTH2_ORGANIZATION_MAP,omitempty"`
+	AzureADTeamMap         map[string]any `json:"SOCIAL_AUTH_AZUREAD_OAUTH2_TEAM_MAP,omitempty"`
+
+	RadiusServer     string `json:"RADIUS_SERVER,omitempty"`
+	RadiusSecret     string `json:"RADIUS_SECRET,omitempty"`
+	TacacsPlusHost   string `json:"TACACSPLUS_HOST,omitempty"`
+	TacacsPlusSecret string `json:"TACACSPLUS_SECRET,omitempty"`
+
+	Ldap []LdapSettings `json:"-"`
+}
+
+func (s *Settings) UnmarshalJSON(data []byte) error {
+	type settings Settings
+	err := json.Unmarshal(data, (*settings)(s))
+	if err != nil {
+		return err
+	}
+
+	var raw map[string]json.RawMessage
+	err = json.Unmarshal(data, &raw)
+	if err != nil {
+		return err
+	}
+
+	// NOTE: LDAP servers share the same keys with a different prefix,
+	// they are regrouped under their own object to be handled the same way.
+	s.Ldap = nil
+	for _, index := range LdapServerIndexes {
+		prefix := "AUTH_LDAP_"
+		if index != "" {
+			prefix += index + "_"
+		}
+
+		ldapRaw := make(map[string]json.RawMessage)
+		for key, value := range raw {
+			if strings.HasPrefix(key, prefix) {
+				ldapRaw[strings.TrimPrefix(key, prefix)] = value
+			}
+		}
+
+		ldapData, err := json.Marshal(ldapRaw)
+		if err != nil {
+			return err
+		}
+
+		ldapSettings := LdapSettings{Index: index}
+		err = json.Unmarshal(ldapData, &ldapSettings)
+		if err != nil {
+			return err
+		}
+
+		if ldapSettings.ServerUri != "" {
+			s.Ldap = append(s.Ldap, ldapSettings)
+		}
+	}
+
+	return nil
+}
+
+func (s Settings) MarshalJSON() ([]byte, error) {
+	type settings Settings
+	return json.MarshalIndent((settings)(s), "", "  ")
+}
+
+// Secrets are returned as `$encrypted$` by AWX/Tower, only whether they are set is kept.
+func isSet(secret string) string {
+	return strconv.FormatBool(secret != "")
+}
+
+func toJSONString(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil || string(data) == "null" {
+		return ""
+	}
+	return string(data)
+}
+
+func (s *Settings) SetProperties(props *properties.Properties) {
+	props.SetProperty("settings_collected", strconv.FormatBool(true))
+	props.SetProperty("disable_local_auth", strconv.FormatBool(s.DisableLocalAuth))
+	props.SetProperty("auth_basic_enabled", strconv.FormatBool(s.AuthBasicEnabled))
+	props.SetProperty("session_cookie_age", strconv.FormatInt(int64(s.SessionCookieAge), 10))
+	props.SetProperty("sessions_per_user", strconv.FormatInt(int64(s.SessionsPerUser), 10))
+	props.SetProperty("allow_oauth2_for_external_users", strconv.FormatBool(s.AllowOAuth2ForExternalUsers))
+	props.SetProperty("oauth2_provider", toJSONString(s.OAuth2Provider))
+	props.SetProperty("remote_host_headers", strings.Join(s.RemoteHostHeaders, ", "))
+
+	var ldapServers []string
+	for _, ldap := range s.Ldap {
+		prefix := ldap.PropertyPrefix()
+		ldapServers = append(ldapServers, ldap.ServerUri)
+		props.SetProperty(prefix+"server_uri", ldap.ServerUri)
+		props.SetProperty(prefix+"bind_dn", ldap.BindDn)
+		props.SetProperty(prefix+"bind_password_is_set", isSet(ldap.BindPassword))
+		props.SetProperty(prefix+"start_tls", strconv.FormatBool(ldap.StartTls))
+		props.SetProperty(prefix+"user_search", toJSONString(ldap.UserSearch))
+		props.SetProperty(prefix+"user_dn_template", ldap.UserDnTemplate)
+		props.SetProperty(prefix+"group_search", toJSONString(ldap.GroupSearch))
+		props.SetProperty(prefix+"group_type", ldap.GroupType)
+		props.SetProperty(prefix+"require_group", ldap.RequireGroup)
+		props.SetProperty(prefix+"deny_group", ldap.DenyGroup)
+		props.SetProperty(prefix+"user_flags_by_group", toJSONString(ldap.UserFlagsByGroup))
+		props.SetProperty(prefix+"organization_map", toJSONString(ldap.OrganizationMap))
+		props.SetProperty(prefix+"team_map", toJSONString(ldap.TeamMap))
+	}
+	props.SetProperty("ldap_enabled", strconv.FormatBool(len(s.Ldap) > 0))
+	props.SetProperty("ldap_servers", strings.Join(ldapServers, ", "))
+
+	var samlIdps []string
+	for name := range s.SamlEnabledIdps {
+		samlIdps = append(samlIdps, name)
+	}
+	sort.Strings(samlIdps)
+	props.SetProperty("saml_enabled", strconv.FormatBool(len(s.SamlEnabledIdps) > 0))
+	props.SetProperty("saml_sp_entity_id", s.SamlSpEntityId)
+	props.SetProperty("saml_sp_private_key_is_set", isSet(s.SamlSpPrivateKey))
+	props.SetProperty("saml_enabled_idps", strings.Join(samlIdps, ", "))
+	props.SetProperty("saml_organization_map", toJSONString(s.SamlOrganizationMap))
+	props.SetProperty("saml_team_map", toJSONString(s.SamlTeamMap))
+	props.SetProperty("saml_organization_attr", toJSONString(s.SamlOrganizationAttr))
+	props.SetProperty("saml_team_attr", toJSONString(s.SamlTeamAttr))
+	props.SetProperty("saml_user_flags_by_attr", toJSONString(s.SamlUserFlagsByAttr))
+
+	props.SetProperty("oidc_enabled", strconv.FormatBool(s.OidcKey != ""))
+	props.SetProperty("oidc_key", s.OidcKey)
+	props.SetProperty("oidc_secret_is_set", isSet(s.OidcSecret))
+	props.SetProperty("oidc_endpoint", s.OidcEndpoint)
+	props.SetProperty("oidc_verify_ssl", strconv.FormatBool(s.OidcVerifySSL))
+
+	props.SetProperty("azuread_oauth2_enabled", strconv.FormatBool(s.AzureADOAuth2Key != ""))
+	props.SetProperty("azuread_oauth2_key", s.AzureADOAuth2Key)
+	props.SetProperty("azuread_oauth2_secret_is_set", isSet(s.AzureADOAuth2Secret))
+	props.SetProperty("azuread_oauth2_organization_map", toJSONString(s.AzureADOrganizationMap))
+	props.SetProperty("azuread_oauth2_team_map", toJSONString(s.AzureADTeamMap))
+
+	props.SetProperty("radius_server", s.RadiusServer)
+	props.SetProperty("radius_secret_is_set", isSet(s.RadiusSecret))
+	props.SetProperty("tacacsplus_host", s.TacacsPlusHost)
+	props.SetProperty("tacacsplus_secret_is_set", isSet(s.TacacsPlusSecret))
+}
@@ -0,0 +1,120 @@
+package ansible
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Ramoreik/gopengraph/properties"
+	"gopkg.in/yaml.v3"
+)
+
+const ANSIBLE_HOST_VARIABLE = "ansible_host"
+const ANSIBLE_PORT_VARIABLE = "ansible_port"
+const ANSIBLE_GROUP_PRIORITY_VARIABLE = "ansible_group_priority"
+const VAULT_PREFIX = "$ANSIBLE_VAULT"
+
+// Variables defining how and as whom Ansible connects to a host.
+var CONNECTION_VARIABLES = []string{
+	ANSIBLE_HOST_VARIABLE,
+	ANSIBLE_PORT_VARIABLE,
+	"ansible_user",
+	"ansible_connection",
+	"ansible_become_user",
+	"ansible_winrm_transport",
+	"ansible_ssh_private_key_file",
+}
+
+// Parses the variables of a Host or Group, AWX/Tower stores them either as YAML or JSON.
+func ParseVariables(variables string) map[string]any {
+	parsed := make(map[string]any)
+	if strings.TrimSpace(variables) == "" {
+		return parsed
+	}
+	// NOTE: YAML being a superset of JSON, both formats are handled by the same parser.
+	err := yaml.Unmarshal([]byte(variables), &parsed)
+	if err != nil {
+		return make(map[string]any)
+	}
+	return parsed
+}
+
+// Returns true when the value is encrypted with Ansible Vault, either a whole
+// file or a value tagged with `!vault`.
+func IsVaultEncrypted(value string) bool {
+	return strings.HasPrefix(strings.TrimSpace(value), VAULT_PREFIX)
+}
+
+// Returns the keys of the variables holding a vault encrypted value, sorted.
+func VaultKeys(variables string) (keys []string) {
+	var walk func(path string, value any)
+	walk = func(path string, value any) {
+		switch typed := value.(type) {
+		case map[string]any:
+			for key, child := range typed {
+				walk(joinPath(path, key), child)
+			}
+		case []any:
+			for i, child := range typed {
+				walk(path+"["+strconv.Itoa(i)+"]", child)
+			}
+		case string:
+			if IsVaultEncrypted(typed) {
+				keys = append(keys, path)
+			}
+		}
+	}
+	walk("", ParseVariables(variables))
+	slices.Sort(keys)
+	return keys
+}
+
+func setVaultProperties(props *properties.Properties, variables string) {
+	vaultKeys := VaultKeys(variables)
+	props.SetProperty("has_vault", strconv.FormatBool(len(vaultKeys) > 0))
+	props.SetProperty("vault_keys", strings.Join(vaultKeys, ", "))
+}
+
+// Returns true when the value is an IP address rather than a host name.
+func IsIPAddress(value string) bool {
+	return net.ParseIP(strings.Trim(value, "[]")) != nil
+}
+
+// Returns the connection variables found in variables as strings, `ansible_port`
+// is normalized to its integer value when possible.
+func ConnectionVariables(variables string) map[string]string {
+	connectionVariables := make(map[string]string)
+	parsed := ParseVariables(variables)
+	for _, name := range CONNECTION_VARIABLES {
+		value, ok := parsed[name]
+		if !ok || value == nil {
+			continue
+		}
+		if name == ANSIBLE_PORT_VARIABLE {
+			if port, err := strconv.Atoi(fmt.Sprint(value)); err == nil {
+				connectionVariables[name] = strconv.Itoa(port)
+				continue
+			}
+		}
+		connectionVariables[name] = fmt.Sprint(value)
+	}
+	return connectionVariables
+}
+
+// Returns `ansible_group_priority`, groups with a higher priority override the
+// variables of groups of the same depth. The default priority is 1.
+func GroupPriority(variables string) int {
+	priority, err := strconv.Atoi(fmt.Sprint(ParseVariables(variables)[ANSIBLE_GROUP_PRIORITY_VARIABLE]))
+	if err != nil {
+		return 1
+	}
+	return priority
+}
+
+func setConnectionProperties(props *properties.Properties, variables string) {
+	for name, value := range ConnectionVariables(variables) {
+		props.SetProperty(name, value)
+	}
+}
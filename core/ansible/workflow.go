@@ -46,7 +46,7 @@ func (w *WorkflowJobTemplate) ToBHNode() (n *node.Node) {
 	props.SetProperty("description", w.Description)
 	props.SetProperty("url", w.Url)
 	props.SetProperty("organization", strconv.FormatInt(int64(w.Organization), 10))
-	props.SetProperty("extra_vars", w.ExtraVars)
+	Secrets.SetVariablesProperty(props, "extra_vars", w.ExtraVars)
 	props.SetProperty("status", w.Status)
 	props.SetProperty("last_job_run", w.LastJobRun)
 	props.SetProperty("next_job_run", w.NextJobRun)
@@ -0,0 +1,56 @@
+package gather
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+const AZUREHOUND_SERVICE_PRINCIPAL = "AZServicePrincipal"
+
+type AzureHoundFile struct {
+	Data []struct {
+		Kind string `json:"kind"`
+		Data struct {
+			Id          string `json:"id"`
+			AppId       string `json:"appId"`
+			DisplayName string `json:"displayName"`
+		} `json:"data"`
+	} `json:"data"`
+}
+
+// Loads the service principals of AzureHound output, the result maps the lowercase
+// application id to the object id of the service principal.
+func LoadAzureHound(paths []string) (servicePrincipals map[string]string, err error) {
+
+	servicePrincipals = make(map[string]string)
+
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load `%s`: %w", path, err)
+		}
+		content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+
+		var azureHoundFile AzureHoundFile
+		err = json.Unmarshal(content, &azureHoundFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load `%s`: %w", path, err)
+		}
+
+		for _, object := range azureHoundFile.Data {
+			if object.Kind == AZUREHOUND_SERVICE_PRINCIPAL && object.Data.AppId != "" && object.Data.Id != "" {
+				// NOTE: BloodHound stores Entra ID object ids in uppercase.
+				servicePrincipals[strings.ToLower(object.Data.AppId)] = strings.ToUpper(object.Data.Id)
+			}
+		}
+	}
+
+	log.Infof("Loaded %d service principals from AzureHound data.", len(servicePrincipals))
+
+	return servicePrincipals, nil
+}
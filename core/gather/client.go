@@ -189,6 +189,23 @@ func GatherObject[T ansible.AnsibleType](installUUID string, client AHClient,
 	return objectMap, nil
 }
 
+func GatherOrderedObject[T ansible.AnsibleType](installUUID string, client AHClient,
+	target url.URL, endpoint string) (objectList []T, err error) {
+	// NOTE: Some endpoints are ordered lists (EX: Galaxy Credentials of an Organization),
+	// mapping them by ID would lose the order so the slice is kept as is.
+
+	objectList, err = Gather[T](client, target, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, object := range objectList {
+		object.InitOID(installUUID)
+	}
+
+	return objectList, nil
+}
+
 func GatherAnsibleInstance(client AHClient, target url.URL) (instance ansible.AnsibleInstance, err error) {
 
 	url := target.String() + PING_ENDPOINT
@@ -219,6 +236,138 @@ func GatherAnsibleInstance(client AHClient, target url.URL) (instance ansible.An
 	return instance, nil
 }
 
+func GatherSettings(client AHClient, target url.URL) (settings *ansible.Settings, err error) {
+
+	url := target.String() + SETTINGS_ENDPOINT
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// NOTE: Only System Administrators and System Auditors can read the settings.
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP error occurred: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	settings = &ansible.Settings{}
+	err = json.Unmarshal(body, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func GatherHostFacts(client AHClient, target url.URL, hostID int) (facts map[string]any, err error) {
+
+	url := target.String() + fmt.Sprintf(HOST_FACTS_ENDPOINT, hostID)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP error occurred: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(body, &facts)
+	if err != nil {
+		return nil, err
+	}
+
+	return facts, nil
+}
+
+// Downloads the output of a job, the output is truncated to `maxSize` bytes.
+func GatherJobOutput(client AHClient, target url.URL, jobID int, maxSize int64) (output string, truncated bool, err error) {
+
+	url := target.String() + fmt.Sprintf(JOB_STDOUT_ENDPOINT, jobID)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return "", false, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", false, fmt.Errorf("HTTP error occurred: %s", resp.Status)
+	}
+
+	// NOTE: One more byte is read to know whether the output was truncated.
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return "", false, err
+	}
+	if int64(len(body)) > maxSize {
+		return string(body[:maxSize]), true, nil
+	}
+
+	return string(body), false, nil
+}
+
+// Gathers the artifacts of a job, they are only returned by the job details.
+func GatherJobArtifacts(client AHClient, target url.URL, jobID int) (artifacts map[string]any, err error) {
+
+	url := target.String() + fmt.Sprintf(JOB_ENDPOINT, jobID)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP error occurred: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var job ansible.Job
+	err = json.Unmarshal(body, &job)
+	if err != nil {
+		return nil, err
+	}
+
+	return job.Artifacts, nil
+}
+
 func HasAccessTo[T ansible.AnsibleType](objectMap map[int]T, ID int) (result bool) {
 	// NOTE: If ID = 0, then the resource is not bound to a resource of this type.
 	// EX: A Credential can exist without being bound to an Organization.
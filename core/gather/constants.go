@@ -5,25 +5,39 @@ import "fmt"
 const API_ENDPOINT = "/api/v2/"
 const ME_ENDPOINT = API_ENDPOINT + "me/"
 const ORGANIZATIONS_ENDPOINT = API_ENDPOINT + "organizations/"
+const ORGANIZATION_GALAXY_CREDENTIALS_ENDPOINT = API_ENDPOINT + "organizations/%d/galaxy_credentials/"
 const PROJECTS_ENDPOINT = API_ENDPOINT + "projects/"
 const INVENTORIES_ENDPOINT = API_ENDPOINT + "inventories/"
+const INVENTORY_HOSTS_ENDPOINT = API_ENDPOINT + "inventories/%d/hosts/"
+const INVENTORY_INPUT_INVENTORIES_ENDPOINT = API_ENDPOINT + "inventories/%d/input_inventories/"
+const CONSTRUCTED_INVENTORIES_ENDPOINT = API_ENDPOINT + "constructed_inventories/"
 const JOB_TEMPLATE_ENDPOINT = API_ENDPOINT + "job_templates/"
 const CREDENTIALS_ENDPOINT = API_ENDPOINT + "credentials/"
+const CREDENTIAL_INPUT_SOURCES_ENDPOINT = API_ENDPOINT + "credential_input_sources/"
 const CREDENTIAL_TYPES_ENDPOINT = API_ENDPOINT + "credential_types/"
 const USERS_ENDPOINT = API_ENDPOINT + "users/"
 const USER_ROLES_ENDPOINT = API_ENDPOINT + "users/%d/roles/"
 const GROUPS_ENDPOINT = API_ENDPOINT + "groups/"
 const GROUP_HOSTS_ENDPOINT = API_ENDPOINT + "groups/%d/hosts/"
+const GROUP_CHILDREN_ENDPOINT = API_ENDPOINT + "groups/%d/children/"
 const JOBS_ENDPOINT = API_ENDPOINT + "jobs/"
+const JOB_ENDPOINT = API_ENDPOINT + "jobs/%d/"
+
+// NOTE: `txt` is replaced by a placeholder past the display limit of AWX/Tower, `txt_download` is not.
+const JOB_STDOUT_ENDPOINT = API_ENDPOINT + "jobs/%d/stdout/?format=txt_download"
 const WORKFLOW_JOB_TEMPLATES_ENDPOINT = API_ENDPOINT + "workflow_job_templates/"
 const WORKFLOW_JOB_TEMPLATE_NODES_ENDPOINT = API_ENDPOINT + "workflow_job_template_nodes/"
 const HOSTS_ENDPOINT = API_ENDPOINT + "hosts/"
+const INVENTORY_SOURCES_ENDPOINT = API_ENDPOINT + "inventory_sources/"
+const INVENTORY_SOURCE_HOSTS_ENDPOINT = API_ENDPOINT + "inventory_sources/%d/hosts/"
+const HOST_FACTS_ENDPOINT = API_ENDPOINT + "hosts/%d/ansible_facts/"
 const TEAMS_ENDPOINT = API_ENDPOINT + "teams/"
 const TEAM_ROLES_ENDPOINT = API_ENDPOINT + "teams/%d/roles/"
 const TEAM_USERS_ENDPOINT = API_ENDPOINT + "teams/%d/users/"
 const JOB_TEMPLATE_CREDENTIALS_ENDPOINT = API_ENDPOINT + "job_templates/%d/credentials/"
 
 const PING_ENDPOINT = API_ENDPOINT + "ping"
+const SETTINGS_ENDPOINT = API_ENDPOINT + "settings/all/"
 
 const PAGE_SIZE = 200
 
@@ -0,0 +1,658 @@
+package gather
+
+import (
+	"ansible-hound/core/ansible"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+const AWX_EXPORT_NAMESPACE = "awx_export"
+
+// Assets of `awx export`, in the order their IDs are assigned.
+var EXPORT_ASSET_TYPES = []string{
+	"organizations", "users", "teams", "credential_types", "credentials",
+	"projects", "inventory", "job_templates", "workflow_job_templates",
+}
+
+// Fields of exported assets referencing another asset by its natural key.
+var EXPORT_REFERENCE_FIELDS = []string{
+	"organization", "inventory", "project", "credential", "credential_type", "webhook_credential",
+	"signature_validation_credential", "source_credential", "target_credential", "unified_job_template",
+	"workflow_job_template", "execution_environment", "default_environment",
+}
+
+// Credential types managed by AWX/Tower are not exported, credentials only reference them by
+// name. They are mapped to their namespace, which is the kind of the credentials using them.
+var MANAGED_CREDENTIAL_TYPES = map[string]string{
+	"Machine":                          "ssh",
+	"Source Control":                   "scm",
+	"Vault":                            "vault",
+	"Network":                          "net",
+	"Amazon Web Services":              "aws",
+	"OpenStack":                        "openstack",
+	"VMware vCenter":                   "vmware",
+	"Red Hat Satellite 6":              "satellite6",
+	"Google Compute Engine":            "gce",
+	"Microsoft Azure Resource Manager": "azure_rm",
+	"GitHub Personal Access Token":     "github_token",
+	"GitLab Personal Access Token":     "gitlab_token",
+	"Bitbucket Data Center HTTP Access Token": "bitbucket_dc_token",
+	"Insights":                                    "insights",
+	"Red Hat Virtualization":                      "rhv",
+	"Red Hat Ansible Automation Platform":         "controller",
+	"OpenShift or Kubernetes API Bearer Token":    "kubernetes_bearer_token",
+	"Container Registry":                          "registry",
+	"Ansible Galaxy/Automation Hub API Token":     ansible.GALAXY_CREDENTIAL_KIND,
+	"GPG Public Key":                              "gpg_public_key",
+	"HashiCorp Vault Secret Lookup":               "hashivault_kv",
+	"HashiCorp Vault Signed SSH":                  "hashivault_ssh",
+	"Microsoft Azure Key Vault":                   "azure_kv",
+	"CyberArk Conjur Secrets Manager Lookup":      "conjur",
+	"CyberArk Central Credential Provider Lookup": "aim",
+	"Thycotic DevOps Secrets Vault":               "thycotic_dsv",
+	"Thycotic Secret Server":                      "thycotic_tss",
+	"Centrify Vault Credential Provider Lookup":   "centrify_vault",
+	"AWS Secrets Manager lookup":                  "aws_secretsmanager_credential",
+}
+
+// AwxExport holds the assets of `awx export` files mapped onto the objects of the API. Exports
+// have no numeric IDs: IDs are assigned per type in the order of natural keys, and OIDs are
+// derived from the name of the export, the type and the name of each object.
+type AwxExport struct {
+	Instance                 *ansible.AnsibleInstance
+	Organizations            map[int]*ansible.Organization
+	Users                    map[int]*ansible.User
+	Teams                    map[int]*ansible.Team
+	CredentialTypes          map[int]*ansible.CredentialType
+	Credentials              map[int]*ansible.Credential
+	CredentialInputSources   map[int]*ansible.CredentialInputSource
+	Projects                 map[int]*ansible.Project
+	Inventories              map[int]*ansible.Inventory
+	Hosts                    map[int]*ansible.Host
+	Groups                   map[int]*ansible.Group
+	JobTemplates             map[int]*ansible.JobTemplate
+	WorkflowJobTemplates     map[int]*ansible.WorkflowJobTemplate
+	WorkflowJobTemplateNodes map[int]*ansible.WorkflowJobTemplateNode
+
+	assets   map[string][]map[string]any
+	ids      map[string]int
+	counters map[string]int
+}
+
+// Loads `awx export` files, assets exported in several files are merged. The name identifies
+// the exported instance, objects of exports sharing a name get the same OIDs.
+func LoadAwxExport(paths []string, name string) (export *AwxExport, err error) {
+
+	export = newAwxExport(name)
+	for _, path := range paths {
+		err = export.loadFile(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	export.build()
+
+	log.Infof("Loaded %d organizations, %d users, %d teams, %d credentials, %d projects, %d inventories, %d job templates and %d workflow job templates.",
+		len(export.Organizations), len(export.Users), len(export.Teams), len(export.Credentials),
+		len(export.Projects), len(export.Inventories), len(export.JobTemplates), len(export.WorkflowJobTemplates))
+
+	return export, nil
+}
+
+func newAwxExport(name string) *AwxExport {
+
+	instance := &ansible.AnsibleInstance{
+		Object: ansible.Object{
+			Name: name,
+			Type: "instance",
+		},
+	}
+	instance.InitNamedOID(AWX_EXPORT_NAMESPACE)
+
+	return &AwxExport{
+		Instance:                 instance,
+		Organizations:            make(map[int]*ansible.Organization),
+		Users:                    make(map[int]*ansible.User),
+		Teams:                    make(map[int]*ansible.Team),
+		CredentialTypes:          make(map[int]*ansible.CredentialType),
+		Credentials:              make(map[int]*ansible.Credential),
+		CredentialInputSources:   make(map[int]*ansible.CredentialInputSource),
+		Projects:                 make(map[int]*ansible.Project),
+		Inventories:              make(map[int]*ansible.Inventory),
+		Hosts:                    make(map[int]*ansible.Host),
+		Groups:                   make(map[int]*ansible.Group),
+		JobTemplates:             make(map[int]*ansible.JobTemplate),
+		WorkflowJobTemplates:     make(map[int]*ansible.WorkflowJobTemplate),
+		WorkflowJobTemplateNodes: make(map[int]*ansible.WorkflowJobTemplateNode),
+		assets:                   make(map[string][]map[string]any),
+		ids:                      make(map[string]int),
+		counters:                 make(map[string]int),
+	}
+}
+
+// Maps the loaded assets onto objects, once every asset has an ID.
+func (e *AwxExport) build() {
+	e.registerIDs()
+
+	e.buildOrganizations()
+	e.buildUsers()
+	e.buildTeams()
+	e.buildCredentialTypes()
+	e.buildCredentials()
+	e.buildProjects()
+	e.buildInventories()
+	e.buildJobTemplates()
+	e.buildWorkflowJobTemplates()
+	e.buildRoles()
+}
+
+func (e *AwxExport) loadFile(path string) error {
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to load `%s`: %w", path, err)
+	}
+	return e.parse(content, path)
+}
+
+// Parses the content of an export file, assets already loaded from another file are skipped.
+func (e *AwxExport) parse(content []byte, path string) error {
+
+	var parsed map[string]json.RawMessage
+	err := json.Unmarshal(content, &parsed)
+	if err != nil {
+		return fmt.Errorf("unable to parse `%s`: %w", path, err)
+	}
+
+	for _, assetType := range EXPORT_ASSET_TYPES {
+		if _, ok := parsed[assetType]; !ok {
+			continue
+		}
+		var assets []map[string]any
+		err = json.Unmarshal(parsed[assetType], &assets)
+		if err != nil {
+			return fmt.Errorf("unable to parse the %s of `%s`: %w", assetType, path, err)
+		}
+		// NOTE: Assets exported in several files are only kept once.
+		for _, asset := range assets {
+			key := naturalKey(asset["natural_key"])
+			if !slices.ContainsFunc(e.assets[assetType], func(known map[string]any) bool {
+				return naturalKey(known["natural_key"]) == key
+			}) {
+				e.assets[assetType] = append(e.assets[assetType], asset)
+			}
+		}
+	}
+	return nil
+}
+
+// Assigns an ID to every asset, including hosts, groups and workflow nodes nested in their
+// parent, and managed credential types which are only referenced.
+func (e *AwxExport) registerIDs() {
+
+	for _, assetType := range EXPORT_ASSET_TYPES {
+		slices.SortFunc(e.assets[assetType], func(a map[string]any, b map[string]any) int {
+			return strings.Compare(naturalKey(a["natural_key"]), naturalKey(b["natural_key"]))
+		})
+		for _, asset := range e.assets[assetType] {
+			e.register(asset["natural_key"])
+		}
+	}
+
+	var credentialTypes []map[string]any
+	for _, credential := range e.assets["credentials"] {
+		credentialType, ok := credential["credential_type"].(map[string]any)
+		if ok && e.ids[naturalKey(credentialType)] == 0 {
+			credentialTypes = append(credentialTypes, map[string]any{
+				"name":        credentialType["name"],
+				"kind":        credentialType["kind"],
+				"managed":     true,
+				"natural_key": credentialType,
+			})
+			e.register(credentialType)
+		}
+	}
+	e.assets["credential_types"] = append(e.assets["credential_types"], credentialTypes...)
+
+	// NOTE: Input sources have no natural key, they are identified by the field they set.
+	for _, credential := range e.assets["credentials"] {
+		for _, inputSource := range related(credential, "input_sources") {
+			inputSource["natural_key"] = map[string]any{
+				"target_credential": credential["natural_key"],
+				"name":              inputSource["input_field_name"],
+				"type":              "credential_input_source",
+			}
+		}
+	}
+
+	for _, nested := range []struct{ assetType, related string }{
+		{"inventory", "hosts"},
+		{"inventory", "groups"},
+		{"workflow_job_templates", "workflow_nodes"},
+		{"credentials", "input_sources"},
+	} {
+		var children []map[string]any
+		for _, asset := range e.assets[nested.assetType] {
+			children = append(children, related(asset, nested.related)...)
+		}
+		slices.SortFunc(children, func(a map[string]any, b map[string]any) int {
+			return strings.Compare(naturalKey(a["natural_key"]), naturalKey(b["natural_key"]))
+		})
+		for _, child := range children {
+			e.register(child["natural_key"])
+		}
+	}
+}
+
+func (e *AwxExport) register(key any) int {
+	typedKey, _ := key.(map[string]any)
+	if typedKey == nil {
+		return 0
+	}
+	encodedKey := naturalKey(typedKey)
+	if _, ok := e.ids[encodedKey]; !ok {
+		objectType, _ := typedKey["type"].(string)
+		e.counters[objectType]++
+		e.ids[encodedKey] = e.counters[objectType]
+	}
+	return e.ids[encodedKey]
+}
+
+// Returns the ID of the asset referenced by a natural key, 0 when it is not part of the export.
+func (e *AwxExport) reference(key any) int {
+	if key == nil {
+		return 0
+	}
+	return e.ids[naturalKey(key)]
+}
+
+// Initializes the object of an asset from its fields and its natural key. Names are only
+// unique within the parents of the asset (EX: the organization of a Job Template), so the
+// natural keys of the parents are part of the namespace of the OID.
+func (e *AwxExport) decode(asset map[string]any, object *ansible.Object, target any) error {
+
+	fields := make(map[string]any)
+	for key, value := range asset {
+		if key == "related" || key == "natural_key" || slices.Contains(EXPORT_REFERENCE_FIELDS, key) {
+			continue
+		}
+		fields[key] = value
+	}
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(raw, target)
+	if err != nil {
+		return err
+	}
+
+	key, _ := asset["natural_key"].(map[string]any)
+	object.ID = e.ids[naturalKey(key)]
+	object.Type, _ = key["type"].(string)
+	if object.Name == "" {
+		object.Name, _ = key["name"].(string)
+	}
+
+	parents := make(map[string]any)
+	for field, value := range key {
+		if _, ok := value.(map[string]any); ok {
+			parents[field] = value
+		}
+	}
+	namespace := e.Instance.OID
+	if len(parents) > 0 {
+		namespace += "_" + naturalKey(parents)
+	}
+	object.InitNamedOID(namespace)
+	return nil
+}
+
+func (e *AwxExport) buildOrganizations() {
+	for _, asset := range e.assets["organizations"] {
+		organization := &ansible.Organization{}
+		if !e.decodeOrSkip(asset, &organization.Object, organization) {
+			continue
+		}
+		e.Organizations[organization.ID] = organization
+	}
+}
+
+func (e *AwxExport) buildUsers() {
+	for _, asset := range e.assets["users"] {
+		user := &ansible.User{Roles: make(map[int]*ansible.Role)}
+		username, _ := asset["username"].(string)
+		user.Name = username
+		if !e.decodeOrSkip(asset, &user.Object, user) {
+			continue
+		}
+		e.Users[user.ID] = user
+	}
+}
+
+func (e *AwxExport) buildTeams() {
+	for _, asset := range e.assets["teams"] {
+		team := &ansible.Team{
+			Members: make(map[int]*ansible.User),
+			Roles:   make(map[int]*ansible.Role),
+		}
+		if !e.decodeOrSkip(asset, &team.Object, team) {
+			continue
+		}
+		team.Organization = e.reference(asset["organization"])
+		e.Teams[team.ID] = team
+	}
+}
+
+func (e *AwxExport) buildCredentialTypes() {
+	for _, asset := range e.assets["credential_types"] {
+		credentialType := &ansible.CredentialType{}
+		if !e.decodeOrSkip(asset, &credentialType.Object, credentialType) {
+			continue
+		}
+		if credentialType.Namespace == "" {
+			credentialType.Namespace = MANAGED_CREDENTIAL_TYPES[credentialType.Name]
+		}
+		credentialType.Cloud = credentialType.Kind == "cloud"
+		credentialType.Kubernetes = credentialType.Kind == "kubernetes"
+		e.CredentialTypes[credentialType.ID] = credentialType
+	}
+}
+
+func (e *AwxExport) buildCredentials() {
+	for _, asset := range e.assets["credentials"] {
+		credential := &ansible.Credential{}
+		if !e.decodeOrSkip(asset, &credential.Object, credential) {
+			continue
+		}
+		credential.Organization = e.reference(asset["organization"])
+		credential.CredentialType = e.reference(asset["credential_type"])
+		if credentialType, ok := e.CredentialTypes[credential.CredentialType]; ok {
+			credential.Kind = credentialType.Namespace
+			credential.Managed = credentialType.Managed
+			credential.Cloud = credentialType.Cloud
+			credential.Kubernetes = credentialType.Kubernetes
+		}
+		e.Credentials[credential.ID] = credential
+	}
+
+	for _, asset := range e.assets["credentials"] {
+		for _, inputSource := range related(asset, "input_sources") {
+			credentialInputSource := &ansible.CredentialInputSource{}
+			if !e.decodeOrSkip(inputSource, &credentialInputSource.Object, credentialInputSource) {
+				continue
+			}
+			credentialInputSource.TargetCredential = e.reference(asset["natural_key"])
+			credentialInputSource.SourceCredential = e.reference(inputSource["source_credential"])
+			e.CredentialInputSources[credentialInputSource.ID] = credentialInputSource
+		}
+	}
+
+	for _, asset := range e.assets["organizations"] {
+		organization, ok := e.Organizations[e.reference(asset["natural_key"])]
+		if !ok {
+			continue
+		}
+		for _, galaxyCredential := range related(asset, "galaxy_credentials") {
+			if credential, ok := e.Credentials[e.reference(galaxyCredential)]; ok {
+				organization.GalaxyCredentials = append(organization.GalaxyCredentials, credential)
+			}
+		}
+	}
+}
+
+func (e *AwxExport) buildProjects() {
+	for _, asset := range e.assets["projects"] {
+		project := &ansible.Project{}
+		if !e.decodeOrSkip(asset, &project.Object, project) {
+			continue
+		}
+		project.Organization = e.reference(asset["organization"])
+		project.Credential = e.reference(asset["credential"])
+		project.SignatureValidationCredential = e.reference(asset["signature_validation_credential"])
+		e.Projects[project.ID] = project
+	}
+}
+
+// Builds inventories along with their hosts and groups. Hosts of smart inventories are
+// selected by their filter when the inventory is used, so they are not part of the export.
+func (e *AwxExport) buildInventories() {
+	for _, asset := range e.assets["inventory"] {
+		inventory := &ansible.Inventory{
+			InputInventories: make(map[int]*ansible.Inventory),
+		}
+		if !e.decodeOrSkip(asset, &inventory.Object, inventory) {
+			continue
+		}
+		inventory.Organization = e.reference(asset["organization"])
+		e.Inventories[inventory.ID] = inventory
+
+		for _, hostAsset := range related(asset, "hosts") {
+			host := &ansible.Host{}
+			if !e.decodeOrSkip(hostAsset, &host.Object, host) {
+				continue
+			}
+			host.Inventory = inventory.ID
+			e.Hosts[host.ID] = host
+			inventory.TotalHosts++
+		}
+
+		for _, groupAsset := range related(asset, "groups") {
+			group := &ansible.Group{
+				Hosts:    make(map[int]*ansible.Host),
+				Children: make(map[int]*ansible.Group),
+			}
+			if !e.decodeOrSkip(groupAsset, &group.Object, group) {
+				continue
+			}
+			group.Inventory = inventory.ID
+			e.Groups[group.ID] = group
+			inventory.TotalGroups++
+		}
+	}
+
+	for _, asset := range e.assets["inventory"] {
+		inventory, ok := e.Inventories[e.reference(asset["natural_key"])]
+		if !ok {
+			continue
+		}
+		for _, inputInventory := range related(asset, "input_inventories") {
+			if input, ok := e.Inventories[e.reference(inputInventory)]; ok {
+				inventory.InputInventories[input.ID] = input
+			}
+		}
+		for _, groupAsset := range related(asset, "groups") {
+			group, ok := e.Groups[e.reference(groupAsset["natural_key"])]
+			if !ok {
+				continue
+			}
+			for _, hostKey := range related(groupAsset, "hosts") {
+				if host, ok := e.Hosts[e.reference(hostKey)]; ok {
+					group.Hosts[host.ID] = host
+				}
+			}
+			for _, childKey := range related(groupAsset, "children") {
+				if child, ok := e.Groups[e.reference(childKey)]; ok {
+					group.Children[child.ID] = child
+				}
+			}
+		}
+	}
+
+	for _, group := range e.Groups {
+		group.AllHosts = len(group.DescendantHosts(e.Groups))
+	}
+	// NOTE: Inventory sources are not imported, constructed inventories are only resolved from their hosts.
+	for _, inventory := range e.Inventories {
+		if inventory.Kind == ansible.CONSTRUCTED_INVENTORY_KIND {
+			inventory.Hosts = ResolveConstructedHosts(inventory, e.Hosts, nil)
+		}
+	}
+}
+
+func (e *AwxExport) buildJobTemplates() {
+	for _, asset := range e.assets["job_templates"] {
+		jobTemplate := &ansible.JobTemplate{Credentials: make(map[int]*ansible.Credential)}
+		if !e.decodeOrSkip(asset, &jobTemplate.Object, jobTemplate) {
+			continue
+		}
+		jobTemplate.Organization = e.reference(asset["organization"])
+		jobTemplate.Inventory = e.reference(asset["inventory"])
+		jobTemplate.Project = e.reference(asset["project"])
+		jobTemplate.WebhookCredential = e.reference(asset["webhook_credential"])
+		for _, credentialKey := range related(asset, "credentials") {
+			if credential, ok := e.Credentials[e.reference(credentialKey)]; ok {
+				jobTemplate.Credentials[credential.ID] = credential
+			}
+		}
+		// NOTE: The organization of Job Templates is read-only, it is only part of their natural
+		// key. Job Templates without an organization belong to the one of their project.
+		if key, ok := asset["natural_key"].(map[string]any); ok && jobTemplate.Organization == 0 {
+			jobTemplate.Organization = e.reference(key["organization"])
+		}
+		if jobTemplate.Organization == 0 && HasAccessTo(e.Projects, jobTemplate.Project) {
+			jobTemplate.Organization = e.Projects[jobTemplate.Project].Organization
+		}
+		e.JobTemplates[jobTemplate.ID] = jobTemplate
+	}
+}
+
+func (e *AwxExport) buildWorkflowJobTemplates() {
+	for _, asset := range e.assets["workflow_job_templates"] {
+		workflowJobTemplate := &ansible.WorkflowJobTemplate{}
+		if !e.decodeOrSkip(asset, &workflowJobTemplate.Object, workflowJobTemplate) {
+			continue
+		}
+		workflowJobTemplate.Organization = e.reference(asset["organization"])
+		workflowJobTemplate.Inventory = e.reference(asset["inventory"])
+		workflowJobTemplate.WebhookCredential = e.reference(asset["webhook_credential"])
+		e.WorkflowJobTemplates[workflowJobTemplate.ID] = workflowJobTemplate
+
+		for _, nodeAsset := range related(asset, "workflow_nodes") {
+			workflowJobTemplateNode := &ansible.WorkflowJobTemplateNode{}
+			workflowJobTemplateNode.Name, _ = nodeAsset["identifier"].(string)
+			if !e.decodeOrSkip(nodeAsset, &workflowJobTemplateNode.Object, workflowJobTemplateNode) {
+				continue
+			}
+			workflowJobTemplateNode.WorkflowJobTemplate = workflowJobTemplate.ID
+			workflowJobTemplateNode.UnifiedJobTemplate = e.reference(nodeAsset["unified_job_template"])
+			workflowJobTemplateNode.Inventory = e.reference(nodeAsset["inventory"])
+			for _, nextNode := range related(nodeAsset, "success_nodes") {
+				workflowJobTemplateNode.SuccessNodes = append(workflowJobTemplateNode.SuccessNodes, e.reference(nextNode))
+			}
+			for _, nextNode := range related(nodeAsset, "failure_nodes") {
+				workflowJobTemplateNode.FailureNodes = append(workflowJobTemplateNode.FailureNodes, e.reference(nextNode))
+			}
+			for _, nextNode := range related(nodeAsset, "always_nodes") {
+				workflowJobTemplateNode.AlwaysNodes = append(workflowJobTemplateNode.AlwaysNodes, e.reference(nextNode))
+			}
+			e.WorkflowJobTemplateNodes[workflowJobTemplateNode.ID] = workflowJobTemplateNode
+		}
+	}
+}
+
+// Grants the exported roles to the users and teams holding them. Roles are exported with the
+// asset they apply to and list their users and teams, roles listed under a user or a team
+// are held by it.
+func (e *AwxExport) buildRoles() {
+
+	roles := make(map[string]*ansible.Role)
+	for _, assetType := range EXPORT_ASSET_TYPES {
+		for _, asset := range e.assets[assetType] {
+			for _, relatedRoles := range []string{"roles", "object_roles"} {
+				for _, roleAsset := range related(asset, relatedRoles) {
+					key, _ := roleAsset["natural_key"].(map[string]any)
+					contentObject, _ := key["content_object"].(map[string]any)
+					if contentObject == nil {
+						continue
+					}
+
+					encodedKey := naturalKey(key)
+					role, ok := roles[encodedKey]
+					if !ok {
+						role = &ansible.Role{
+							Object: ansible.Object{ID: len(roles) + 1, Type: "role"},
+						}
+						role.Name, _ = roleAsset["name"].(string)
+						if role.Name == "" {
+							role.Name, _ = key["name"].(string)
+						}
+						role.SummaryFields.ResourceType, _ = contentObject["type"].(string)
+						role.SummaryFields.ResourceId = e.reference(contentObject)
+						role.SummaryFields.ResourceName, _ = contentObject["name"].(string)
+						roles[encodedKey] = role
+					}
+
+					holders := append(related(roleAsset, "users"), related(roleAsset, "teams")...)
+					// NOTE: Roles of users and teams are those granted to them, not those applying to them.
+					self, _ := asset["natural_key"].(map[string]any)
+					if (assetType == "users" || assetType == "teams") && relatedRoles == "roles" &&
+						self != nil && naturalKey(self) != naturalKey(contentObject) {
+						holders = append(holders, self)
+					}
+					for _, holder := range holders {
+						e.grantRole(holder, role)
+					}
+				}
+			}
+		}
+	}
+}
+
+func (e *AwxExport) grantRole(holder map[string]any, role *ansible.Role) {
+	switch holder["type"] {
+	case "user":
+		user, ok := e.Users[e.reference(holder)]
+		if !ok {
+			return
+		}
+		user.Roles[role.ID] = role
+		// NOTE: Members of a team are the users holding its `Member` role.
+		if role.SummaryFields.ResourceType == "team" && role.Name == "Member" {
+			if team, ok := e.Teams[role.SummaryFields.ResourceId]; ok {
+				team.Members[user.ID] = user
+			}
+		}
+	case "team":
+		if team, ok := e.Teams[e.reference(holder)]; ok {
+			team.Roles[role.ID] = role
+		}
+	}
+}
+
+func (e *AwxExport) decodeOrSkip(asset map[string]any, object *ansible.Object, target any) bool {
+	err := e.decode(asset, object, target)
+	if err != nil {
+		log.Errorf("An error occured while loading `%s`, skipping.", naturalKey(asset["natural_key"]))
+		log.Error(err)
+		return false
+	}
+	return true
+}
+
+// Returns the related assets of an asset, either exported in full or as natural keys.
+// Some exports list them at the top level of the asset instead.
+func related(asset map[string]any, name string) (assets []map[string]any) {
+	relatedAssets, _ := asset["related"].(map[string]any)
+	values, ok := relatedAssets[name].([]any)
+	if !ok {
+		values, _ = asset[name].([]any)
+	}
+	for _, value := range values {
+		if typed, ok := value.(map[string]any); ok {
+			assets = append(assets, typed)
+		}
+	}
+	return assets
+}
+
+// Encodes a natural key, keys of JSON objects are sorted so that equal keys are encoded alike.
+func naturalKey(key any) string {
+	encoded, _ := json.Marshal(key)
+	return string(encoded)
+}
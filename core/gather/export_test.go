@@ -0,0 +1,196 @@
+package gather
+
+import (
+	"testing"
+)
+
+const testExport = `{
+  "organizations": [
+    {"name": "Default", "natural_key": {"name": "Default", "type": "organization"},
+     "related": {"roles": [
+       {"name": "Admin", "natural_key": {"content_object": {"name": "Default", "type": "organization"}, "name": "Admin", "type": "role"},
+        "related": {"users": [{"username": "alice", "type": "user"}], "teams": []}}
+     ]}},
+    {"name": "Acme", "natural_key": {"name": "Acme", "type": "organization"}}
+  ],
+  "users": [
+    {"username": "bob", "natural_key": {"username": "bob", "type": "user"},
+     "related": {"roles": [
+       {"name": "Use", "natural_key": {"content_object": {"organization": {"name": "Default", "type": "organization"}, "name": "root", "credential_type": {"name": "Machine", "kind": "ssh", "type": "credential_type"}, "type": "credential"}, "name": "Use", "type": "role"}}
+     ]}},
+    {"username": "alice", "natural_key": {"username": "alice", "type": "user"}}
+  ],
+  "teams": [
+    {"name": "Ops", "organization": {"name": "Default", "type": "organization"},
+     "natural_key": {"organization": {"name": "Default", "type": "organization"}, "name": "Ops", "type": "team"},
+     "related": {
+       "roles": [
+         {"name": "Execute", "natural_key": {"content_object": {"organization": {"name": "Default", "type": "organization"}, "name": "Deploy", "type": "job_template"}, "name": "Execute", "type": "role"}}
+       ],
+       "object_roles": [
+         {"name": "Member", "natural_key": {"content_object": {"organization": {"name": "Default", "type": "organization"}, "name": "Ops", "type": "team"}, "name": "Member", "type": "role"},
+          "related": {"users": [{"username": "bob", "type": "user"}]}}
+       ]}},
+    {"name": "Ops", "organization": {"name": "Acme", "type": "organization"},
+     "natural_key": {"organization": {"name": "Acme", "type": "organization"}, "name": "Ops", "type": "team"}}
+  ],
+  "credentials": [
+    {"name": "root", "organization": {"name": "Default", "type": "organization"},
+     "credential_type": {"name": "Machine", "kind": "ssh", "type": "credential_type"},
+     "natural_key": {"organization": {"name": "Default", "type": "organization"}, "name": "root", "credential_type": {"name": "Machine", "kind": "ssh", "type": "credential_type"}, "type": "credential"}}
+  ],
+  "inventory": [
+    {"name": "Prod", "organization": {"name": "Default", "type": "organization"},
+     "natural_key": {"organization": {"name": "Default", "type": "organization"}, "name": "Prod", "type": "inventory"},
+     "related": {
+       "hosts": [
+         {"name": "web2", "natural_key": {"inventory": {"organization": {"name": "Default", "type": "organization"}, "name": "Prod", "type": "inventory"}, "name": "web2", "type": "host"}},
+         {"name": "web1", "natural_key": {"inventory": {"organization": {"name": "Default", "type": "organization"}, "name": "Prod", "type": "inventory"}, "name": "web1", "type": "host"}}
+       ],
+       "groups": [
+         {"name": "web", "natural_key": {"inventory": {"organization": {"name": "Default", "type": "organization"}, "name": "Prod", "type": "inventory"}, "name": "web", "type": "group"},
+          "related": {"hosts": [{"inventory": {"organization": {"name": "Default", "type": "organization"}, "name": "Prod", "type": "inventory"}, "name": "web1", "type": "host"}], "children": []}}
+       ]}}
+  ],
+  "job_templates": [
+    {"name": "Deploy", "limit": "web",
+     "inventory": {"organization": {"name": "Default", "type": "organization"}, "name": "Prod", "type": "inventory"},
+     "natural_key": {"organization": {"name": "Default", "type": "organization"}, "name": "Deploy", "type": "job_template"},
+     "related": {"credentials": [{"organization": {"name": "Default", "type": "organization"}, "name": "root", "credential_type": {"name": "Machine", "kind": "ssh", "type": "credential_type"}, "type": "credential"}]}}
+  ]
+}`
+
+func loadTestExport(t *testing.T, name string, contents ...string) *AwxExport {
+	export := newAwxExport(name)
+	for _, content := range contents {
+		if err := export.parse([]byte(content), "test.json"); err != nil {
+			t.Fatalf("parse failed: %v", err)
+		}
+	}
+	export.build()
+	return export
+}
+
+func TestRegisterIDs(t *testing.T) {
+	export := loadTestExport(t, "awx", testExport)
+
+	tests := []struct {
+		key string
+		id  int
+	}{
+		// NOTE: IDs are assigned per type in the order of natural keys, not in the order of the file.
+		{`{"name":"Acme","type":"organization"}`, 1},
+		{`{"name":"Default","type":"organization"}`, 2},
+		{`{"type":"user","username":"alice"}`, 1},
+		{`{"type":"user","username":"bob"}`, 2},
+		{`{"kind":"ssh","name":"Machine","type":"credential_type"}`, 1},
+		{`{"inventory":{"name":"Prod","organization":{"name":"Default","type":"organization"},"type":"inventory"},"name":"web1","type":"host"}`, 1},
+		{`{"inventory":{"name":"Prod","organization":{"name":"Default","type":"organization"},"type":"inventory"},"name":"web2","type":"host"}`, 2},
+	}
+	for _, test := range tests {
+		if id := export.ids[test.key]; id != test.id {
+			t.Errorf("ID of %s = %d, expected %d", test.key, id, test.id)
+		}
+	}
+
+	if len(export.Organizations) != 2 || len(export.Users) != 2 || len(export.Teams) != 2 || len(export.Hosts) != 2 {
+		t.Errorf("unexpected object counts: %d organizations, %d users, %d teams, %d hosts",
+			len(export.Organizations), len(export.Users), len(export.Teams), len(export.Hosts))
+	}
+
+	credentialType, ok := export.CredentialTypes[1]
+	if !ok || credentialType.Name != "Machine" || !credentialType.Managed || credentialType.Namespace != "ssh" {
+		t.Errorf("expected the managed `Machine` credential type to be created from its references, got %+v", credentialType)
+	}
+	if credential := export.Credentials[1]; credential == nil || credential.Kind != "ssh" || credential.Organization != 2 {
+		t.Errorf("unexpected credential %+v", credential)
+	}
+
+	jobTemplate := export.JobTemplates[1]
+	if jobTemplate == nil || jobTemplate.Inventory != 1 || jobTemplate.Organization != 2 || len(jobTemplate.Credentials) != 1 {
+		t.Errorf("unexpected references of the job template %+v", jobTemplate)
+	}
+	if group := export.Groups[1]; group == nil || len(group.Hosts) != 1 || group.Hosts[1] == nil || group.AllHosts != 1 {
+		t.Errorf("unexpected hosts of the group %+v", group)
+	}
+}
+
+func TestExportOIDs(t *testing.T) {
+	first := loadTestExport(t, "awx", testExport)
+	second := loadTestExport(t, "awx", testExport)
+	other := loadTestExport(t, "other", testExport)
+
+	for id, organization := range first.Organizations {
+		if organization.OID != second.Organizations[id].OID {
+			t.Errorf("OID of `%s` differs between exports of the same instance", organization.Name)
+		}
+		if organization.OID == other.Organizations[id].OID {
+			t.Errorf("OID of `%s` is shared between exports of different instances", organization.Name)
+		}
+	}
+
+	// NOTE: Team names are only unique within an organization.
+	if first.Teams[1].Name != first.Teams[2].Name || first.Teams[1].OID == first.Teams[2].OID {
+		t.Errorf("expected teams sharing a name in different organizations to get different OIDs")
+	}
+}
+
+func TestParseMergesExports(t *testing.T) {
+	users := `{"users": [{"username": "carol", "natural_key": {"username": "carol", "type": "user"}}, {"username": "alice", "natural_key": {"username": "alice", "type": "user"}}]}`
+	export := loadTestExport(t, "awx", testExport, users)
+
+	if len(export.Users) != 3 {
+		t.Errorf("expected 3 users once exports are merged, got %d", len(export.Users))
+	}
+	if err := newAwxExport("awx").parse([]byte(`{"users": {}}`), "test.json"); err == nil {
+		t.Errorf("expected an error for an invalid export")
+	}
+}
+
+func TestBuildRoles(t *testing.T) {
+	export := loadTestExport(t, "awx", testExport)
+
+	tests := []struct {
+		holder       string
+		role         string
+		resourceType string
+		resourceId   int
+	}{
+		{"user:alice", "Admin", "organization", 2},
+		{"user:bob", "Use", "credential", 1},
+		{"user:bob", "Member", "team", 2},
+		{"team:Ops", "Execute", "job_template", 1},
+	}
+	for _, test := range tests {
+		var granted bool
+		switch test.holder {
+		case "user:alice", "user:bob":
+			for _, user := range export.Users {
+				if "user:"+user.Username != test.holder {
+					continue
+				}
+				for _, role := range user.Roles {
+					granted = granted || role.Name == test.role &&
+						role.SummaryFields.ResourceType == test.resourceType && role.SummaryFields.ResourceId == test.resourceId
+				}
+			}
+		case "team:Ops":
+			for _, role := range export.Teams[2].Roles {
+				granted = granted || role.Name == test.role &&
+					role.SummaryFields.ResourceType == test.resourceType && role.SummaryFields.ResourceId == test.resourceId
+			}
+		}
+		if !granted {
+			t.Errorf("expected `%s` to hold the %s role on %s %d", test.holder, test.role, test.resourceType, test.resourceId)
+		}
+	}
+
+	// NOTE: Team IDs are assigned in the order of natural keys, `Acme/Ops` comes first.
+	team := export.Teams[2]
+	if len(team.Members) != 1 || team.Members[2] == nil || team.Members[2].Username != "bob" {
+		t.Errorf("expected `bob` to be the only member of `Default/Ops`, got %v", team.Members)
+	}
+	if len(export.Teams[1].Roles) != 0 || len(export.Users[1].Roles) != 1 {
+		t.Errorf("unexpected roles granted to `Acme/Ops` or `alice`")
+	}
+}
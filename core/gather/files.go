@@ -0,0 +1,574 @@
+package gather
+
+import (
+	"ansible-hound/core/ansible"
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"gopkg.in/yaml.v3"
+)
+
+const ANSIBLE_CFG = "ansible.cfg"
+const GROUP_VARS_DIR = "group_vars"
+const HOST_VARS_DIR = "host_vars"
+const FILES_NAMESPACE = "files"
+
+// Settings of `ansible.cfg` attached to inventories, mapped to the connection
+// variable they set by default (if any).
+var ANSIBLE_CFG_SETTINGS = map[string]string{
+	"defaults.inventory":                   "",
+	"defaults.remote_user":                 "ansible_user",
+	"defaults.remote_port":                 "ansible_port",
+	"defaults.private_key_file":            "ansible_ssh_private_key_file",
+	"defaults.transport":                   "ansible_connection",
+	"defaults.host_key_checking":           "",
+	"defaults.ask_pass":                    "",
+	"defaults.vault_password_file":         "",
+	"defaults.vault_identity_list":         "",
+	"privilege_escalation.become":          "",
+	"privilege_escalation.become_method":   "",
+	"privilege_escalation.become_user":     "ansible_become_user",
+	"privilege_escalation.become_ask_pass": "",
+}
+
+// Extensions ignored in inventory directories, like Ansible does by default.
+var INVENTORY_IGNORE_EXTENSIONS = []string{
+	".pyc", ".pyo", ".swp", ".bak", "~", ".rpm", ".md", ".txt", ".rst", ".orig", ".cfg", ".retry",
+}
+
+var VARIABLES_EXTENSIONS = []string{"", ".yml", ".yaml", ".json"}
+
+// Matches `host:port`, colons of host ranges are ignored (EX: `web[01:10].corp:2222`).
+var hostPortPattern = regexp.MustCompile(`^((?:[^:\[\]]|\[[^\]]*\])+):([0-9]+)$`)
+
+// Matches host ranges (EX: `web[01:10]`, `db-[a:c]`, `node[0:10:2]`).
+var hostRangePattern = regexp.MustCompile(`\[([0-9a-zA-Z]+):([0-9a-zA-Z]+)(?::([0-9]+))?\]`)
+
+// Loads the settings of `ansible.cfg` listed in ANSIBLE_CFG_SETTINGS, keyed by their name.
+func LoadAnsibleConfig(path string) (config map[string]string, err error) {
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load `%s`: %w", path, err)
+	}
+
+	config = make(map[string]string)
+	section := ""
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			key, value, ok = strings.Cut(line, ":")
+		}
+		if !ok {
+			continue
+		}
+		key = strings.TrimSpace(key)
+		if _, ok := ANSIBLE_CFG_SETTINGS[section+"."+key]; ok {
+			config[key] = strings.TrimSpace(value)
+		}
+	}
+
+	return config, scanner.Err()
+}
+
+// Returns the connection variables set by default in `ansible.cfg`, serialized as JSON.
+func ConfigVariables(config map[string]string) string {
+	variables := make(map[string]any)
+	for setting, variable := range ANSIBLE_CFG_SETTINGS {
+		_, key, _ := strings.Cut(setting, ".")
+		if value, ok := config[key]; ok && variable != "" {
+			variables[variable] = value
+		}
+	}
+	return encodeVariables(variables)
+}
+
+// fileGroup is a group as declared in inventory files, before IDs are assigned.
+type fileGroup struct {
+	hosts     []string
+	children  []string
+	variables map[string]any
+}
+
+// fileInventory is an inventory loaded from an ini or YAML file, or from a directory of such files.
+type fileInventory struct {
+	groups        map[string]*fileGroup
+	hosts         []string
+	hostVariables map[string]map[string]any
+}
+
+func (i *fileInventory) group(name string) *fileGroup {
+	if _, ok := i.groups[name]; !ok {
+		i.groups[name] = &fileGroup{variables: make(map[string]any)}
+	}
+	return i.groups[name]
+}
+
+func (i *fileInventory) addHost(name string, groupName string, variables map[string]any) {
+	if _, ok := i.hostVariables[name]; !ok {
+		i.hosts = append(i.hosts, name)
+		i.hostVariables[name] = make(map[string]any)
+	}
+	for key, value := range variables {
+		i.hostVariables[name][key] = value
+	}
+	if group := i.group(groupName); !slices.Contains(group.hosts, name) {
+		group.hosts = append(group.hosts, name)
+	}
+}
+
+func (i *fileInventory) addChild(groupName string, childName string) {
+	i.group(childName)
+	if group := i.group(groupName); !slices.Contains(group.children, childName) {
+		group.children = append(group.children, childName)
+	}
+}
+
+// Loads static inventories (ini or YAML files, or directories of such files) along with their
+// `group_vars` and `host_vars`. Each path is loaded as an Inventory, `ansible.cfg` settings
+// are attached to every inventory.
+func LoadInventoryFiles(paths []string, config map[string]string) (inventories map[int]*ansible.Inventory,
+	groups map[int]*ansible.Group, hosts map[int]*ansible.Host, err error) {
+
+	inventories = make(map[int]*ansible.Inventory)
+	groups = make(map[int]*ansible.Group)
+	hosts = make(map[int]*ansible.Host)
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("unable to load `%s`: %w", path, err)
+		}
+
+		loaded := &fileInventory{
+			groups:        make(map[string]*fileGroup),
+			hostVariables: make(map[string]map[string]any),
+		}
+		variablesDir := path
+		if info.IsDir() {
+			err = loaded.loadDir(path)
+		} else {
+			err = loaded.loadFile(path)
+			variablesDir = filepath.Dir(path)
+		}
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		loaded.loadVariables(variablesDir)
+
+		inventory := &ansible.Inventory{
+			Object: ansible.Object{
+				ID:   len(inventories) + 1,
+				Name: filepath.ToSlash(filepath.Clean(path)),
+				Type: "inventory",
+			},
+			AnsibleConfig:   config,
+			ConfigVariables: ConfigVariables(config),
+		}
+		inventory.InitNamedOID(FILES_NAMESPACE)
+		inventories[inventory.ID] = inventory
+
+		loaded.build(inventory, groups, hosts)
+
+		log.Infof("Loaded %d hosts and %d groups from `%s`.", inventory.TotalHosts, inventory.TotalGroups, path)
+	}
+
+	return inventories, groups, hosts, nil
+}
+
+func (i *fileInventory) loadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("unable to load `%s`: %w", dir, err)
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() {
+			if entry.Name() == GROUP_VARS_DIR || entry.Name() == HOST_VARS_DIR || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			err = i.loadDir(path)
+		} else {
+			if slices.ContainsFunc(INVENTORY_IGNORE_EXTENSIONS, func(extension string) bool {
+				return strings.HasSuffix(entry.Name(), extension)
+			}) || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+			err = i.loadFile(path)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *fileInventory) loadFile(path string) error {
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("unable to load `%s`: %w", path, err)
+	}
+
+	switch {
+	case bytes.HasPrefix(content, []byte("#!")):
+		log.Warnf("Skipping dynamic inventory script `%s`.", path)
+		return nil
+	case ansible.IsVaultEncrypted(string(content)):
+		log.Warnf("Skipping vault encrypted inventory `%s`.", path)
+		return nil
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yml", ".yaml", ".json":
+		err = i.parseYaml(content)
+	default:
+		err = i.parseIni(content)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to parse `%s`: %w", path, err)
+	}
+	return nil
+}
+
+// Parses an ini inventory: hosts are listed under `[group]`, variables under `[group:vars]`
+// and child groups under `[group:children]`. Hosts listed before any section are ungrouped.
+func (i *fileInventory) parseIni(content []byte) error {
+
+	groupName, sectionType := ansible.UNGROUPED_GROUP, "hosts"
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			groupName, sectionType, _ = strings.Cut(line[1:len(line)-1], ":")
+			if sectionType == "" {
+				sectionType = "hosts"
+			}
+			i.group(groupName)
+			continue
+		}
+
+		switch sectionType {
+		case "vars":
+			key, value, ok := strings.Cut(line, "=")
+			if !ok {
+				return fmt.Errorf("invalid variable `%s` in group `%s`", line, groupName)
+			}
+			value = strings.TrimSpace(value)
+			if len(value) > 1 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+				value = value[1 : len(value)-1]
+			}
+			i.group(groupName).variables[strings.TrimSpace(key)] = value
+		case "children":
+			i.addChild(groupName, strings.Fields(line)[0])
+		case "hosts":
+			fields := splitIniHostLine(line)
+			// NOTE: A line holding only an empty quoted string has no host.
+			if len(fields) == 0 {
+				return fmt.Errorf("invalid host line `%s`", line)
+			}
+			variables := make(map[string]any)
+			for _, field := range fields[1:] {
+				key, value, ok := strings.Cut(field, "=")
+				if !ok {
+					return fmt.Errorf("invalid variable `%s` of host `%s`", field, fields[0])
+				}
+				variables[key] = value
+			}
+			hostPattern := fields[0]
+			// NOTE: `host:port` sets the port of the host, IPv6 addresses are left untouched.
+			if match := hostPortPattern.FindStringSubmatch(hostPattern); match != nil {
+				hostPattern = match[1]
+				variables[ansible.ANSIBLE_PORT_VARIABLE] = match[2]
+			}
+			for _, name := range expandHostRange(hostPattern) {
+				i.addHost(name, groupName, variables)
+			}
+		default:
+			return fmt.Errorf("invalid section type `%s` of group `%s`", sectionType, groupName)
+		}
+	}
+	return scanner.Err()
+}
+
+// Splits a host line on whitespace, quoted values can hold spaces.
+func splitIniHostLine(line string) (fields []string) {
+	var field strings.Builder
+	var quote rune
+	for _, r := range line {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote == 0 && (r == '"' || r == '\''):
+			quote = r
+		case quote == 0 && r == '#':
+			// NOTE: Comments start with a `#` outside of quotes.
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+			}
+			return fields
+		case quote == 0 && (r == ' ' || r == '\t'):
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+		default:
+			field.WriteRune(r)
+		}
+	}
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+	return fields
+}
+
+// Expands host ranges, numeric ranges keep the padding of their start (EX: `web[01:03]`).
+func expandHostRange(pattern string) (names []string) {
+	match := hostRangePattern.FindStringSubmatchIndex(pattern)
+	if match == nil {
+		return []string{pattern}
+	}
+	prefix, suffix := pattern[:match[0]], pattern[match[1]:]
+	start, end := pattern[match[2]:match[3]], pattern[match[4]:match[5]]
+	step := 1
+	if match[6] != -1 {
+		step, _ = strconv.Atoi(pattern[match[6]:match[7]])
+		step = max(step, 1)
+	}
+
+	var values []string
+	startNumber, startErr := strconv.Atoi(start)
+	endNumber, endErr := strconv.Atoi(end)
+	switch {
+	case startErr == nil && endErr == nil:
+		for n := startNumber; n <= endNumber; n += step {
+			values = append(values, fmt.Sprintf("%0*d", len(start), n))
+		}
+	case len(start) == 1 && len(end) == 1:
+		for c := int(start[0]); c <= int(end[0]); c += step {
+			values = append(values, string(rune(c)))
+		}
+	default:
+		return []string{pattern}
+	}
+
+	for _, value := range values {
+		names = append(names, expandHostRange(prefix+value+suffix)...)
+	}
+	return names
+}
+
+// Parses a YAML inventory, each top-level key is a group holding `hosts`, `vars` and `children`.
+func (i *fileInventory) parseYaml(content []byte) error {
+	var parsed map[string]any
+	err := yaml.Unmarshal(content, &parsed)
+	if err != nil {
+		return err
+	}
+	for groupName, group := range parsed {
+		i.parseYamlGroup(groupName, group)
+	}
+	return nil
+}
+
+func (i *fileInventory) parseYamlGroup(groupName string, content any) {
+	i.group(groupName)
+	group, _ := content.(map[string]any)
+
+	groupHosts, _ := group["hosts"].(map[string]any)
+	for hostPattern, hostContent := range groupHosts {
+		variables, _ := hostContent.(map[string]any)
+		for _, name := range expandHostRange(hostPattern) {
+			i.addHost(name, groupName, variables)
+		}
+	}
+
+	groupVariables, _ := group["vars"].(map[string]any)
+	for key, value := range groupVariables {
+		i.group(groupName).variables[key] = value
+	}
+
+	children, _ := group["children"].(map[string]any)
+	for childName, child := range children {
+		i.addChild(groupName, childName)
+		i.parseYamlGroup(childName, child)
+	}
+}
+
+// Loads `group_vars` and `host_vars`, variables of files override those of the inventory.
+// The implicit `all` and `ungrouped` groups exist even when the inventory does not declare them.
+func (i *fileInventory) loadVariables(dir string) {
+	i.group(ansible.ALL_GROUP)
+	i.group(ansible.UNGROUPED_GROUP)
+	for name, group := range i.groups {
+		for key, value := range loadVariablesFiles(filepath.Join(dir, GROUP_VARS_DIR), name) {
+			group.variables[key] = value
+		}
+	}
+	for _, name := range i.hosts {
+		for key, value := range loadVariablesFiles(filepath.Join(dir, HOST_VARS_DIR), name) {
+			i.hostVariables[name][key] = value
+		}
+	}
+}
+
+// Loads the variables of a group or host, stored either in `<name>`, `<name>.yml` or in every
+// file of the `<name>` directory. Vault encrypted files cannot be read, they are recorded
+// under their path so that they are reported as vault encrypted.
+func loadVariablesFiles(dir string, name string) (variables map[string]any) {
+
+	variables = make(map[string]any)
+
+	var files []string
+	for _, extension := range VARIABLES_EXTENSIONS {
+		path := filepath.Join(dir, name+extension)
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && slices.Contains(VARIABLES_EXTENSIONS, filepath.Ext(entry.Name())) {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+
+	for _, path := range files {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			log.Error(err)
+			continue
+		}
+		if ansible.IsVaultEncrypted(string(content)) {
+			header, _, _ := strings.Cut(string(content), "\n")
+			variables[filepath.ToSlash(filepath.Join(filepath.Base(dir), name, filepath.Base(path)))] = header
+			continue
+		}
+		var fileVariables map[string]any
+		err = yaml.Unmarshal(content, &fileVariables)
+		if err != nil {
+			log.Errorf("Unable to parse `%s`, skipping.", path)
+			log.Error(err)
+			continue
+		}
+		for key, value := range fileVariables {
+			variables[key] = value
+		}
+	}
+
+	return variables
+}
+
+// Assigns IDs to the groups and hosts of the inventory, sorted by name so that they are reproducible.
+func (i *fileInventory) build(inventory *ansible.Inventory, groups map[int]*ansible.Group, hosts map[int]*ansible.Host) {
+
+	// NOTE: The `all` group holds the variables of the inventory itself.
+	if all, ok := i.groups[ansible.ALL_GROUP]; ok {
+		inventory.Variables = encodeVariables(all.variables)
+	}
+
+	inventoryHosts := make(map[string]*ansible.Host)
+	hostNames := slices.Clone(i.hosts)
+	slices.Sort(hostNames)
+	for _, name := range hostNames {
+		host := &ansible.Host{
+			Object: ansible.Object{
+				ID:   len(hosts) + 1,
+				Name: name,
+				Type: "host",
+			},
+			Inventory: inventory.ID,
+			Enabled:   true,
+			Variables: encodeVariables(i.hostVariables[name]),
+		}
+		host.InitNamedOID(inventory.OID)
+		hosts[host.ID] = host
+		inventoryHosts[name] = host
+	}
+
+	inventoryGroups := make(map[string]*ansible.Group)
+	var groupNames []string
+	for name, group := range i.groups {
+		// NOTE: `all` and `ungrouped` are implicit, `ungrouped` is only kept when it holds variables.
+		if name == ansible.ALL_GROUP || name == ansible.UNGROUPED_GROUP && len(group.variables) == 0 {
+			continue
+		}
+		groupNames = append(groupNames, name)
+	}
+	slices.Sort(groupNames)
+	for _, name := range groupNames {
+		group := &ansible.Group{
+			Object: ansible.Object{
+				ID:   len(groups) + 1,
+				Name: name,
+				Type: "group",
+			},
+			Inventory: inventory.ID,
+			Variables: encodeVariables(i.groups[name].variables),
+			Hosts:     make(map[int]*ansible.Host),
+			Children:  make(map[int]*ansible.Group),
+		}
+		group.InitNamedOID(inventory.OID)
+		groups[group.ID] = group
+		inventoryGroups[name] = group
+	}
+
+	for name, group := range inventoryGroups {
+		for _, hostName := range i.groups[name].hosts {
+			host := inventoryHosts[hostName]
+			group.Hosts[host.ID] = host
+		}
+		for _, childName := range i.groups[name].children {
+			if child, ok := inventoryGroups[childName]; ok {
+				group.Children[child.ID] = child
+			}
+		}
+	}
+	for _, group := range inventoryGroups {
+		group.AllHosts = len(group.DescendantHosts(groups))
+	}
+
+	inventory.TotalHosts = len(inventoryHosts)
+	inventory.TotalGroups = len(inventoryGroups)
+}
+
+func encodeVariables(variables map[string]any) string {
+	if len(variables) == 0 {
+		return ""
+	}
+	content, err := json.Marshal(variables)
+	if err != nil {
+		return ""
+	}
+	return string(content)
+}
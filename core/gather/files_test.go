@@ -0,0 +1,172 @@
+package gather
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func newFileInventory() *fileInventory {
+	return &fileInventory{
+		groups:        make(map[string]*fileGroup),
+		hostVariables: make(map[string]map[string]any),
+	}
+}
+
+func TestExpandHostRange(t *testing.T) {
+	tests := []struct {
+		pattern string
+		names   []string
+	}{
+		{"web1", []string{"web1"}},
+		{"web[1:3]", []string{"web1", "web2", "web3"}},
+		{"web[01:03].corp", []string{"web01.corp", "web02.corp", "web03.corp"}},
+		{"node[0:6:3]", []string{"node0", "node3", "node6"}},
+		{"db-[a:c]", []string{"db-a", "db-b", "db-c"}},
+		{"rack[1:2]-[a:b]", []string{"rack1-a", "rack1-b", "rack2-a", "rack2-b"}},
+		{"web[3:1]", nil},
+		{"web[aa:bb]", []string{"web[aa:bb]"}},
+	}
+	for _, test := range tests {
+		names := expandHostRange(test.pattern)
+		if !slices.Equal(names, test.names) {
+			t.Errorf("expandHostRange(%q) = %q, expected %q", test.pattern, names, test.names)
+		}
+	}
+}
+
+func TestHostPortPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		host    string
+		port    string
+	}{
+		{"web1:2222", "web1", "2222"},
+		{"web[01:10].corp:2222", "web[01:10].corp", "2222"},
+		{"web[01:10].corp", "", ""},
+		{"web1", "", ""},
+		{"fe80::1", "", ""},
+	}
+	for _, test := range tests {
+		var host, port string
+		if match := hostPortPattern.FindStringSubmatch(test.pattern); match != nil {
+			host, port = match[1], match[2]
+		}
+		if host != test.host || port != test.port {
+			t.Errorf("hostPortPattern(%q) = (%q, %q), expected (%q, %q)", test.pattern, host, port, test.host, test.port)
+		}
+	}
+}
+
+func TestSplitIniHostLine(t *testing.T) {
+	tests := []struct {
+		line   string
+		fields []string
+	}{
+		{"web1", []string{"web1"}},
+		{"web1 ansible_host=10.0.0.1\tansible_user=deploy", []string{"web1", "ansible_host=10.0.0.1", "ansible_user=deploy"}},
+		{`web1 motd="hello world" # comment`, []string{"web1", "motd=hello world"}},
+		{`web1 tag='#1'`, []string{"web1", "tag=#1"}},
+	}
+	for _, test := range tests {
+		fields := splitIniHostLine(test.line)
+		if !slices.Equal(fields, test.fields) {
+			t.Errorf("splitIniHostLine(%q) = %q, expected %q", test.line, fields, test.fields)
+		}
+	}
+}
+
+func TestParseIni(t *testing.T) {
+	content := `
+bastion ansible_port=2200
+
+[web]
+web[1:2].corp
+db1.corp:2222 ansible_user=dba
+
+[web:vars]
+http_port="8080"
+
+[prod:children]
+web
+
+; comment
+[prod:vars]
+env=prod
+`
+	inventory := newFileInventory()
+	if err := inventory.parseIni([]byte(content)); err != nil {
+		t.Fatalf("parseIni failed: %v", err)
+	}
+
+	if hosts := inventory.groups["web"].hosts; !slices.Equal(hosts, []string{"web1.corp", "web2.corp", "db1.corp"}) {
+		t.Errorf("unexpected hosts of `web`: %q", hosts)
+	}
+	if hosts := inventory.groups["ungrouped"].hosts; !slices.Equal(hosts, []string{"bastion"}) {
+		t.Errorf("unexpected ungrouped hosts: %q", hosts)
+	}
+	if children := inventory.groups["prod"].children; !slices.Equal(children, []string{"web"}) {
+		t.Errorf("unexpected children of `prod`: %q", children)
+	}
+	if value := inventory.groups["web"].variables["http_port"]; value != "8080" {
+		t.Errorf("unexpected `http_port` of `web`: %v", value)
+	}
+	if value := inventory.groups["prod"].variables["env"]; value != "prod" {
+		t.Errorf("unexpected `env` of `prod`: %v", value)
+	}
+
+	expectedVariables := map[string]map[string]any{
+		"bastion":   {"ansible_port": "2200"},
+		"db1.corp":  {"ansible_port": "2222", "ansible_user": "dba"},
+		"web1.corp": {},
+	}
+	for host, expected := range expectedVariables {
+		variables := inventory.hostVariables[host]
+		if len(variables) != len(expected) {
+			t.Errorf("unexpected variables of `%s`: %v", host, variables)
+			continue
+		}
+		for key, value := range expected {
+			if variables[key] != value {
+				t.Errorf("unexpected `%s` of `%s`: %v, expected %v", key, host, variables[key], value)
+			}
+		}
+	}
+}
+
+func TestParseIniErrors(t *testing.T) {
+	tests := []string{
+		"[web:vars]\nhttp_port",
+		"[web]\nweb1 ansible_host",
+		"[web:unknown]\nweb1",
+		"[web]\n\"\"",
+		"[web]\n''",
+	}
+	for _, content := range tests {
+		if err := newFileInventory().parseIni([]byte(content)); err == nil {
+			t.Errorf("parseIni(%q) expected an error", content)
+		}
+	}
+}
+
+func TestLoadVariablesImplicitGroups(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, GROUP_VARS_DIR), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, GROUP_VARS_DIR, "all.yml"), []byte("ansible_user: admin\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	inventory := newFileInventory()
+	if err := inventory.parseIni([]byte("[web]\nweb1\n")); err != nil {
+		t.Fatalf("parseIni failed: %v", err)
+	}
+	inventory.loadVariables(dir)
+
+	all, ok := inventory.groups["all"]
+	if !ok || all.variables["ansible_user"] != "admin" {
+		t.Errorf("expected `group_vars/all.yml` to be loaded without an `all` section")
+	}
+}
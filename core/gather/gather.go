@@ -4,6 +4,7 @@ import (
 	"ansible-hound/core/ansible"
 	"fmt"
 	"net/url"
+	"slices"
 
 	"github.com/charmbracelet/log"
 )
@@ -42,8 +43,10 @@ func GatherUsers(client AHClient, installUUID string,
 	return users, err
 }
 
+// Gathers Hosts along with their `ansible_fqdn` fact when gatherFacts is set, facts
+// are fetched one Host at a time and are only used to link Active Directory computers.
 func GatherHosts(client AHClient, installUUID string,
-	targetUrl url.URL) (hosts map[int]*ansible.Host, err error) {
+	targetUrl url.URL, gatherFacts bool) (hosts map[int]*ansible.Host, err error) {
 
 	log.Info("Gathering Hosts.")
 	hosts, err = GatherObject[*ansible.Host](
@@ -53,11 +56,62 @@ func GatherHosts(client AHClient, installUUID string,
 		log.Error("An error occured while gathering Hosts, skipping.")
 		log.Error(err)
 	}
+	if !gatherFacts {
+		return hosts, err
+	}
+	log.Info("Gathering Host Facts.")
+	for _, host := range hosts {
+		// NOTE: Facts are only available for hosts that were targeted by a job with fact caching.
+		if host.AnsibleFactsModified == "" {
+			continue
+		}
+		facts, err := GatherHostFacts(client, targetUrl, host.ID)
+		if err != nil {
+			log.Error("An error occured while gathering Host Facts, skipping Host.")
+			log.Error(err)
+			continue
+		}
+		if fqdn, ok := facts["ansible_fqdn"].(string); ok {
+			host.AnsibleFqdn = fqdn
+		}
+	}
 	return hosts, err
 }
 
+func GatherInventorySources(client AHClient, installUUID string,
+	targetUrl url.URL, hosts map[int]*ansible.Host) (inventorySources map[int]*ansible.InventorySource, err error) {
+
+	log.Info("Gathering Inventory Sources.")
+	inventorySources, err = GatherObject[*ansible.InventorySource](
+		installUUID, client, targetUrl, INVENTORY_SOURCES_ENDPOINT,
+	)
+	if err != nil {
+		log.Error("An error occured while gathering Inventory Sources, skipping.")
+		log.Error(err)
+	}
+	log.Info("Gathering Inventory Source Hosts.")
+	for _, inventorySource := range inventorySources {
+
+		inventorySourceHostsEndpoint := fmt.Sprintf(INVENTORY_SOURCE_HOSTS_ENDPOINT, inventorySource.ID)
+		sourceHosts, err := GatherObject[*ansible.Host](
+			installUUID, client, targetUrl, inventorySourceHostsEndpoint,
+		)
+		if err != nil {
+			log.Error("An error occured while gathering Inventory Source Hosts, skipping Inventory Source.")
+			log.Error(err)
+			continue
+		}
+		for _, sourceHost := range sourceHosts {
+			if HasAccessTo(hosts, sourceHost.ID) {
+				hosts[sourceHost.ID].InventorySource = inventorySource.Source
+			}
+		}
+	}
+	return inventorySources, err
+}
+
 func GatherGroups(client AHClient, installUUID string,
-	targetUrl url.URL) (groups map[int]*ansible.Group, err error) {
+	targetUrl url.URL, hosts map[int]*ansible.Host) (groups map[int]*ansible.Group, err error) {
 
 	log.Info("Gathering Groups.")
 	groups, err = GatherObject[*ansible.Group](
@@ -67,11 +121,36 @@ func GatherGroups(client AHClient, installUUID string,
 		log.Error("An error occured while gathering Groups, skipping.")
 		log.Error(err)
 	}
-	log.Info("Gathering Group Hosts.")
-	for i, group := range groups {
 
+	// NOTE: The direct groups of a host are listed in its summary fields, which avoids one
+	// request per group. Inventories where a host has too many groups to be listed fall back
+	// to the hosts of each group.
+	incompleteInventories := make(map[int]bool)
+	for _, group := range groups {
+		group.Hosts = make(map[int]*ansible.Host)
+		if hosts == nil {
+			incompleteInventories[group.Inventory] = true
+		}
+	}
+	for _, host := range hosts {
+		if !host.SummaryFields.Groups.IsComplete() {
+			incompleteInventories[host.Inventory] = true
+			continue
+		}
+		for _, hostGroup := range host.SummaryFields.Groups.Results {
+			if group, ok := groups[hostGroup.ID]; ok {
+				group.Hosts[host.ID] = host
+			}
+		}
+	}
+
+	log.Info("Gathering Group Hosts.")
+	for _, group := range groups {
+		if !incompleteInventories[group.Inventory] {
+			continue
+		}
 		groupHostsEndpoint := fmt.Sprintf(GROUP_HOSTS_ENDPOINT, group.ID)
-		hosts, err := GatherObject[*ansible.Host](
+		groupHosts, err := GatherObject[*ansible.Host](
 			installUUID, client, targetUrl, groupHostsEndpoint,
 		)
 		if err != nil {
@@ -79,9 +158,27 @@ func GatherGroups(client AHClient, installUUID string,
 			log.Error(err)
 			continue
 		}
-		group.Hosts = hosts
-		groups[i] = group
+		group.Hosts = groupHosts
+	}
+
+	log.Info("Gathering Group Children.")
+	for _, group := range groups {
+		groupChildrenEndpoint := fmt.Sprintf(GROUP_CHILDREN_ENDPOINT, group.ID)
+		children, err := GatherObject[*ansible.Group](
+			installUUID, client, targetUrl, groupChildrenEndpoint,
+		)
+		if err != nil {
+			log.Error("An error occured while gathering Group Children, skipping Group.")
+			log.Error(err)
+			continue
+		}
+		group.Children = children
 	}
+
+	for _, group := range groups {
+		group.AllHosts = len(group.DescendantHosts(groups))
+	}
+
 	return groups, err
 }
 
@@ -99,6 +196,48 @@ func GatherJobs(client AHClient, installUUID string,
 	return jobs, err
 }
 
+// Downloads and scans the output of the most recent jobs of each template,
+// along with their artifacts. The output itself is not kept.
+func GatherJobOutputs(client AHClient, targetUrl url.URL, jobs map[int]*ansible.Job,
+	jobsPerTemplate int, maxSize int64) {
+
+	log.Infof("Gathering Job Outputs of the %d most recent Jobs per template.", jobsPerTemplate)
+
+	templateJobs := make(map[int][]*ansible.Job)
+	for _, job := range jobs {
+		templateJobs[job.UnifiedJobTemplate] = append(templateJobs[job.UnifiedJobTemplate], job)
+	}
+
+	for _, recentJobs := range templateJobs {
+		slices.SortFunc(recentJobs, func(a *ansible.Job, b *ansible.Job) int { return b.ID - a.ID })
+		for _, job := range recentJobs[:min(jobsPerTemplate, len(recentJobs))] {
+
+			output, truncated, err := GatherJobOutput(client, targetUrl, job.ID, maxSize)
+			if err != nil {
+				log.Error("An error occured while gathering Job Output, skipping Job.")
+				log.Error(err)
+				continue
+			}
+			_, job.OutputFindings = ansible.Secrets.ScanText(output)
+			job.OutputScanned = true
+			job.OutputTruncated = truncated
+			if truncated {
+				log.Debugf("Output of Job %d was truncated to %d bytes.", job.ID, maxSize)
+			}
+
+			if job.Artifacts == nil {
+				artifacts, err := GatherJobArtifacts(client, targetUrl, job.ID)
+				if err != nil {
+					log.Error("An error occured while gathering Job Artifacts, skipping.")
+					log.Error(err)
+					continue
+				}
+				job.Artifacts = artifacts
+			}
+		}
+	}
+}
+
 func GatherJobTemplates(client AHClient, installUUID string,
 	targetUrl url.URL) (jobTemplates map[int]*ansible.JobTemplate, err error) {
 
@@ -158,7 +297,8 @@ func GatherWorkflowJobTemplateNodes(client AHClient, installUUID string,
 }
 
 func GatherInventories(client AHClient, installUUID string,
-	targetUrl url.URL) (inventories map[int]*ansible.Inventory, err error) {
+	targetUrl url.URL, hosts map[int]*ansible.Host,
+	inventorySources map[int]*ansible.InventorySource) (inventories map[int]*ansible.Inventory, err error) {
 
 	log.Info("Gathering Inventories.")
 	inventories, err = GatherObject[*ansible.Inventory](
@@ -169,9 +309,113 @@ func GatherInventories(client AHClient, installUUID string,
 		log.Error(err)
 	}
 
+	// NOTE: Smart inventories hold no hosts of their own, AWX/Tower evaluates their
+	// host filter when listing their hosts.
+	log.Info("Gathering Smart Inventory Hosts.")
+	for _, inventory := range inventories {
+		if inventory.Kind != ansible.SMART_INVENTORY_KIND {
+			continue
+		}
+		inventoryHostsEndpoint := fmt.Sprintf(INVENTORY_HOSTS_ENDPOINT, inventory.ID)
+		inventoryHosts, err := GatherObject[*ansible.Host](
+			installUUID, client, targetUrl, inventoryHostsEndpoint,
+		)
+		if err != nil {
+			log.Error("An error occured while gathering Smart Inventory Hosts, skipping Inventory.")
+			log.Error(err)
+			continue
+		}
+		inventory.Hosts = inventoryHosts
+	}
+
+	gatherConstructedInventories(client, installUUID, targetUrl, inventories, hosts, inventorySources)
+
 	return inventories, err
 }
 
+// Gathers the input inventories, limit and compose rules of constructed inventories (AWX 4.x)
+// and resolves the input hosts they are built from.
+func gatherConstructedInventories(client AHClient, installUUID string,
+	targetUrl url.URL, inventories map[int]*ansible.Inventory, hosts map[int]*ansible.Host,
+	inventorySources map[int]*ansible.InventorySource) {
+
+	constructed := false
+	for _, inventory := range inventories {
+		constructed = constructed || inventory.Kind == ansible.CONSTRUCTED_INVENTORY_KIND
+	}
+	if !constructed {
+		return
+	}
+
+	log.Info("Gathering Constructed Inventories.")
+	constructedInventories, err := GatherObject[*ansible.Inventory](
+		installUUID, client, targetUrl, CONSTRUCTED_INVENTORIES_ENDPOINT,
+	)
+	if err != nil {
+		log.Error("An error occured while gathering Constructed Inventories, skipping.")
+		log.Error(err)
+	}
+	for id, constructedInventory := range constructedInventories {
+		if inventory, ok := inventories[id]; ok {
+			inventory.Limit = constructedInventory.Limit
+			inventory.SourceVars = constructedInventory.SourceVars
+		}
+	}
+
+	log.Info("Gathering Constructed Inventory Input Inventories.")
+	for _, inventory := range inventories {
+		if inventory.Kind != ansible.CONSTRUCTED_INVENTORY_KIND {
+			continue
+		}
+		inputInventoriesEndpoint := fmt.Sprintf(INVENTORY_INPUT_INVENTORIES_ENDPOINT, inventory.ID)
+		inputInventories, err := GatherObject[*ansible.Inventory](
+			installUUID, client, targetUrl, inputInventoriesEndpoint,
+		)
+		if err != nil {
+			log.Error("An error occured while gathering Input Inventories, skipping Inventory.")
+			log.Error(err)
+			continue
+		}
+		inventory.InputInventories = inputInventories
+		inventory.Hosts = ResolveConstructedHosts(inventory, hosts, inventorySources)
+	}
+}
+
+// Returns the input hosts a constructed inventory is built from. The constructed inventory
+// holds a copy of each host it selected, copies are matched to input hosts by name. When the
+// inventory was never synced, every host of its input inventories is returned. An inventory
+// which was synced without copies selected no host, and neither does one whose sync is unknown.
+func ResolveConstructedHosts(inventory *ansible.Inventory, hosts map[int]*ansible.Host,
+	inventorySources map[int]*ansible.InventorySource) (inputHosts map[int]*ansible.Host) {
+
+	constructedNames := make(map[string]bool)
+	for _, host := range hosts {
+		if host.Inventory == inventory.ID {
+			constructedNames[host.Name] = true
+		}
+	}
+
+	neverUpdated := false
+	if len(constructedNames) == 0 {
+		for _, inventorySource := range inventorySources {
+			if inventorySource.Inventory == inventory.ID && inventorySource.Source == ansible.CONSTRUCTED_INVENTORY_SOURCE {
+				neverUpdated = inventorySource.IsNeverUpdated()
+			}
+		}
+	}
+
+	inputHosts = make(map[int]*ansible.Host)
+	for _, host := range hosts {
+		if _, ok := inventory.InputInventories[host.Inventory]; !ok {
+			continue
+		}
+		if neverUpdated || constructedNames[host.Name] {
+			inputHosts[host.ID] = host
+		}
+	}
+	return inputHosts
+}
+
 func GatherOrganizations(client AHClient, installUUID string,
 	targetUrl url.URL) (organizations map[int]*ansible.Organization, err error) {
 
@@ -184,6 +428,24 @@ func GatherOrganizations(client AHClient, installUUID string,
 		log.Error(err)
 	}
 
+	log.Info("Gathering Organization Galaxy Credentials.")
+	for i, organization := range organizations {
+
+		galaxyCredentialsEndpoint := fmt.Sprintf(
+			ORGANIZATION_GALAXY_CREDENTIALS_ENDPOINT, organization.ID)
+		galaxyCredentials, err := GatherOrderedObject[*ansible.Credential](
+			installUUID, client, targetUrl, galaxyCredentialsEndpoint,
+		)
+		if err != nil {
+			log.Error("An error occured while gathering Organization Galaxy Credentials.")
+			log.Error(err)
+			continue
+		}
+
+		organization.GalaxyCredentials = galaxyCredentials
+		organizations[i] = organization
+	}
+
 	return organizations, err
 
 }
@@ -202,6 +464,20 @@ func GatherCredentials(client AHClient, installUUID string,
 	return credentials, err
 }
 
+func GatherCredentialInputSources(client AHClient, installUUID string,
+	targetUrl url.URL) (credentialInputSources map[int]*ansible.CredentialInputSource, err error) {
+
+	log.Info("Gathering Credential Input Sources.")
+	credentialInputSources, err = GatherObject[*ansible.CredentialInputSource](
+		installUUID, client, targetUrl, CREDENTIAL_INPUT_SOURCES_ENDPOINT,
+	)
+	if err != nil {
+		log.Error("An error occured while gathering Credential Input Sources, skipping.")
+		log.Error(err)
+	}
+	return credentialInputSources, err
+}
+
 func GatherCredentialTypes(client AHClient, installUUID string,
 	targetUrl url.URL) (credentialTypes map[int]*ansible.CredentialType, err error) {
 
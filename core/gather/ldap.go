@@ -2,52 +2,195 @@ package gather
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/binary"
 	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/go-ldap/ldap/v3"
+	"github.com/go-ldap/ldap/v3/gssapi"
+	"github.com/jcmturner/gokrb5/v8/client"
+	"github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/credentials"
 
 	"github.com/charmbracelet/log"
 )
 
+const LDAP_BATCH_SIZE = 50
+const LDAP_PAGE_SIZE = 500
+const INVALID_SID = "<invalid SID>"
+const USER_ACCOUNT_FILTER = "(sAMAccountType=805306368)"
+const COMPUTER_ACCOUNT_FILTER = "(sAMAccountType=805306369)"
+
+var LDAP_ATTRIBUTES = []string{"distinguishedName", "objectSid", "objectGUID", "sAMAccountName", "dNSHostName"}
+
+const BIND_SIMPLE = "simple"
+const BIND_NTLM = "ntlm"
+const BIND_KERBEROS = "kerberos"
+
+var BIND_METHODS = []string{BIND_SIMPLE, BIND_NTLM, BIND_KERBEROS}
+
+const KRB5_CONF_TEMPLATE = `[libdefaults]
+  default_realm = %[1]s
+  dns_lookup_kdc = false
+  dns_lookup_realm = false
+  udp_preference_limit = 1
+
+[realms]
+  %[1]s = {
+    kdc = %[2]s:88
+  }
+`
+
+const LDAP_PORT = 389
+const LDAPS_PORT = 636
+
 type AHLdap struct {
 	IsLDAPS       bool
-	IP            string
+	StartTLS      bool
+	Host          string
+	Port          int
+	BindMethod    string
 	BindUsername  string
 	BindPassword  string
+	BindHash      string
+	CCachePath    string
 	Domain        string
+	CAFile        string
 	SkipVerifySSL bool
 }
 
-func InitLdap(dc_ip string, username string, password string, domain string, isLDAPS bool, skipVerifySSL bool) AHLdap {
+func InitLdap(address string, bindMethod string, username string, password string, hash string,
+	ccachePath string, domain string, isLDAPS bool, startTLS bool,
+	caFile string, skipVerifySSL bool) (AHLdap, error) {
+
+	// NOTE: Without an explicit bind method, the strongest available authentication material is used.
+	if bindMethod == "" {
+		switch {
+		case ccachePath != "":
+			bindMethod = BIND_KERBEROS
+		case hash != "":
+			bindMethod = BIND_NTLM
+		default:
+			bindMethod = BIND_SIMPLE
+		}
+	}
+
+	host, port, isLDAPS, err := ParseLdapAddress(address, isLDAPS)
+	if err != nil {
+		return AHLdap{}, err
+	}
 
 	ldap := AHLdap{
-		IP:            dc_ip,
+		Host:          host,
+		Port:          port,
+		BindMethod:    bindMethod,
 		BindUsername:  username,
 		BindPassword:  password,
+		BindHash:      hash,
+		CCachePath:    ccachePath,
 		Domain:        domain,
 		IsLDAPS:       isLDAPS,
+		StartTLS:      startTLS && !isLDAPS,
+		CAFile:        caFile,
 		SkipVerifySSL: skipVerifySSL,
 	}
 
-	return ldap
+	return ldap, nil
+}
+
+// Parses the address of the domain controller, it can either be a host, a `host:port`
+// or an `ldap://` / `ldaps://` URL. The scheme of an URL takes precedence over `isLDAPS`.
+func ParseLdapAddress(address string, isLDAPS bool) (host string, port int, ldaps bool, err error) {
+
+	ldaps = isLDAPS
+	portString := ""
+
+	if strings.Contains(address, "://") {
+		ldapUrl, err := url.Parse(address)
+		if err != nil {
+			return "", 0, false, err
+		}
+		switch strings.ToLower(ldapUrl.Scheme) {
+		case "ldap":
+			ldaps = false
+		case "ldaps":
+			ldaps = true
+		default:
+			return "", 0, false, fmt.Errorf("unsupported LDAP scheme `%s`", ldapUrl.Scheme)
+		}
+		host = ldapUrl.Hostname()
+		portString = ldapUrl.Port()
+	} else if splitHost, splitPort, splitErr := net.SplitHostPort(address); splitErr == nil {
+		host = splitHost
+		portString = splitPort
+	} else {
+		host = strings.Trim(address, "[]")
+	}
+
+	if host == "" {
+		return "", 0, false, fmt.Errorf("empty domain controller address")
+	}
+
+	port = LDAP_PORT
+	if ldaps {
+		port = LDAPS_PORT
+	}
+	if portString != "" {
+		port, err = strconv.Atoi(portString)
+		if err != nil {
+			return "", 0, false, fmt.Errorf("invalid LDAP port `%s`", portString)
+		}
+	}
+
+	return host, port, ldaps, nil
+}
+
+func initTLSConfig(ldapObject AHLdap) (*tls.Config, error) {
+
+	tlsConfig := &tls.Config{
+		ServerName:         ldapObject.Host,
+		InsecureSkipVerify: ldapObject.SkipVerifySSL,
+	}
+
+	if ldapObject.CAFile != "" {
+		caBundle, err := os.ReadFile(ldapObject.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(caBundle) {
+			return nil, fmt.Errorf("no valid certificate found in `%s`", ldapObject.CAFile)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	return tlsConfig, nil
 }
 
 func Connect(ldapObject AHLdap) (*ldap.Conn, error) {
 
 	scheme := "ldap"
-	port := 389
 	var dialOpts []ldap.DialOpt
 
+	tlsConfig, err := initTLSConfig(ldapObject)
+	if err != nil {
+		log.Error("Failed to load the LDAP TLS configuration.")
+		log.Error(err)
+		return nil, err
+	}
+
 	if ldapObject.IsLDAPS {
 		scheme = "ldaps"
-		port = 636
-		dialOpts = append(dialOpts, ldap.DialWithTLSConfig(&tls.Config{
-			InsecureSkipVerify: ldapObject.SkipVerifySSL,
-		}))
+		dialOpts = append(dialOpts, ldap.DialWithTLSConfig(tlsConfig))
 	}
 
-	conn, err := ldap.DialURL(fmt.Sprintf("%s://%s:%d", scheme, ldapObject.IP, port), dialOpts...)
+	address := net.JoinHostPort(ldapObject.Host, strconv.Itoa(ldapObject.Port))
+	conn, err := ldap.DialURL(fmt.Sprintf("%s://%s", scheme, address), dialOpts...)
 
 	if err != nil {
 		log.Error("Failed to connect on the domain controller.")
@@ -55,53 +198,293 @@ func Connect(ldapObject AHLdap) (*ldap.Conn, error) {
 		return nil, err
 	}
 
-	dn := ldapObject.Domain + "\\" + ldapObject.BindUsername
+	if ldapObject.StartTLS {
+		err = conn.StartTLS(tlsConfig)
+		if err != nil {
+			log.Error("Failed to negotiate StartTLS with the domain controller.")
+			log.Error(err)
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	// NOTE: Channel binding tokens are not sent for NTLM and Kerberos binds, domain controllers
+	// enforcing channel binding will only accept simple binds over TLS.
+	if (ldapObject.IsLDAPS || ldapObject.StartTLS) && ldapObject.BindMethod != BIND_SIMPLE {
+		log.Warnf("Channel binding is not supported with %s binds, use a simple bind if the domain controller enforces it.", ldapObject.BindMethod)
+	}
 
-	err = conn.Bind(dn, ldapObject.BindPassword)
+	err = bind(conn, ldapObject)
 
 	if err != nil {
-		log.Fatalf("Authentication on the domain controller failed: %v", err)
-		log.Error(err)
+		log.Errorf("Authentication on the domain controller failed (%s bind): %v", ldapObject.BindMethod, err)
+		conn.Close()
 		return nil, err
 	}
 
 	return conn, err
 }
 
-func Search(conn *ldap.Conn, objectDN string) (string, error) {
+func bind(conn *ldap.Conn, ldapObject AHLdap) error {
+
+	switch ldapObject.BindMethod {
+
+	case BIND_SIMPLE:
+		// NOTE: DNs and UPNs are used as is, which also allows binding on non Active Directory servers.
+		bindUsername := ldapObject.BindUsername
+		if !strings.Contains(bindUsername, "=") && !strings.Contains(bindUsername, "@") {
+			bindUsername = ldapObject.Domain + "\\" + bindUsername
+		}
+		return conn.Bind(bindUsername, ldapObject.BindPassword)
+
+	case BIND_NTLM:
+		if ldapObject.BindHash != "" {
+			return conn.NTLMBindWithHash(ldapObject.Domain, ldapObject.BindUsername, ldapObject.BindHash)
+		}
+		return conn.NTLMBind(ldapObject.Domain, ldapObject.BindUsername, ldapObject.BindPassword)
+
+	case BIND_KERBEROS:
+		client, err := initKerberosClient(ldapObject)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+		// NOTE: The SPN must match the hostname of the domain controller, an IP address will usually be rejected.
+		return conn.GSSAPIBind(client, "ldap/"+ldapObject.Host, "")
+	}
+
+	return fmt.Errorf("unknown bind method `%s`, expected one of: %s",
+		ldapObject.BindMethod, strings.Join(BIND_METHODS, ", "))
+}
+
+func initKerberosClient(ldapObject AHLdap) (*gssapi.Client, error) {
+
+	if ldapObject.CCachePath == "" {
+		return nil, fmt.Errorf("no Kerberos ticket cache provided")
+	}
+
+	ccache, err := credentials.LoadCCache(ldapObject.CCachePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE: The configuration is generated from the ticket cache and the domain controller,
+	// a local `krb5.conf` is not needed.
+	realm := strings.ToUpper(ccache.DefaultPrincipal.Realm)
+	krb5conf, err := config.NewFromString(fmt.Sprintf(KRB5_CONF_TEMPLATE, realm, ldapObject.Host))
+	if err != nil {
+		return nil, err
+	}
+
+	krbClient, err := client.NewFromCCache(ccache, krb5conf, client.DisablePAFXFAST(true))
+	if err != nil {
+		return nil, err
+	}
+
+	return &gssapi.Client{Client: krbClient}, nil
+}
+
+type LdapObject struct {
+	DN             string
+	ObjectSid      string
+	ObjectGUID     string
+	SAMAccountName string
+	DNSHostName    string
+}
+
+func entryToLdapObject(entry *ldap.Entry) LdapObject {
+	objectSid := sidBytesToString(entry.GetRawAttributeValue("objectSid"))
+	if objectSid == INVALID_SID {
+		objectSid = ""
+	}
+	return LdapObject{
+		DN:             entry.DN,
+		ObjectSid:      objectSid,
+		ObjectGUID:     guidBytesToString(entry.GetRawAttributeValue("objectGUID")),
+		SAMAccountName: entry.GetAttributeValue("sAMAccountName"),
+		DNSHostName:    entry.GetAttributeValue("dNSHostName"),
+	}
+}
+
+// Normalizes a DN so that DNs differing only by case or spacing are matched.
+func NormalizeDN(objectDN string) string {
+	parsed, err := ldap.ParseDN(objectDN)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(objectDN))
+	}
+	return strings.ToLower(parsed.String())
+}
+
+// Returns the domain part (`DC=` components) of a DN, used as the base of subtree searches.
+func BaseDN(objectDN string) string {
+	parsed, err := ldap.ParseDN(objectDN)
+	if err != nil {
+		return ""
+	}
+
+	var components []string
+	for _, rdn := range parsed.RDNs {
+		for _, attribute := range rdn.Attributes {
+			if strings.EqualFold(attribute.Type, "dc") {
+				components = append(components, "DC="+attribute.Value)
+			}
+		}
+	}
+
+	return strings.Join(components, ",")
+}
+
+// Converts a base DN (`DC=corp,DC=local`) to its domain name (`CORP.LOCAL`).
+func DomainFromBaseDN(baseDN string) string {
+	if baseDN == "" {
+		return ""
+	}
+	var components []string
+	for _, component := range strings.Split(baseDN, ",") {
+		_, value, _ := strings.Cut(component, "=")
+		components = append(components, value)
+	}
+	return strings.ToUpper(strings.Join(components, "."))
+}
+
+// Converts a domain name (`corp.local`) to its base DN (`DC=corp,DC=local`).
+func BaseDNFromDomain(domain string) string {
+	if domain == "" {
+		return ""
+	}
+	var components []string
+	for _, component := range strings.Split(domain, ".") {
+		components = append(components, "DC="+component)
+	}
+	return strings.Join(components, ",")
+}
+
+func searchBatch(conn *ldap.Conn, baseDN string, attribute string,
+	values []string, filter string) (entries []*ldap.Entry, err error) {
+
+	for start := 0; start < len(values); start += LDAP_BATCH_SIZE {
+		end := min(start+LDAP_BATCH_SIZE, len(values))
+
+		var batchFilter strings.Builder
+		batchFilter.WriteString("(&" + filter + "(|")
+		for _, value := range values[start:end] {
+			batchFilter.WriteString(fmt.Sprintf("(%s=%s)", attribute, ldap.EscapeFilter(value)))
+		}
+		batchFilter.WriteString("))")
+
+		searchReq := ldap.NewSearchRequest(
+			baseDN,
+			ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+			batchFilter.String(),
+			LDAP_ATTRIBUTES,
+			nil,
+		)
+
+		// NOTE: A failing batch should not prevent the remaining ones from being resolved.
+		sr, searchErr := conn.SearchWithPaging(searchReq, LDAP_PAGE_SIZE)
+		if searchErr != nil {
+			err = searchErr
+			continue
+		}
+		entries = append(entries, sr.Entries...)
+	}
+
+	return entries, err
+}
+
+// Resolves DNs to their LDAP objects using paged subtree searches, the result
+// is keyed by normalized DN. DNs that could not be found are returned as unresolved.
+func ResolveDNs(conn *ldap.Conn, objectDNs []string) (objects map[string]LdapObject, unresolved []string) {
+
+	objects = make(map[string]LdapObject)
+
+	seen := make(map[string]bool)
+	bases := make(map[string][]string)
+	for _, objectDN := range objectDNs {
+		if seen[NormalizeDN(objectDN)] {
+			continue
+		}
+		seen[NormalizeDN(objectDN)] = true
+		baseDN := BaseDN(objectDN)
+		bases[baseDN] = append(bases[baseDN], objectDN)
+	}
+
+	for baseDN, baseObjectDNs := range bases {
+		if baseDN == "" {
+			log.Warnf("Unable to find the domain of %d DN(s), skipping.", len(baseObjectDNs))
+			continue
+		}
+		entries, err := searchBatch(conn, baseDN, "distinguishedName", baseObjectDNs, "(objectClass=*)")
+		if err != nil {
+			log.Errorf("LDAP search failed on `%s`: %v", baseDN, err)
+		}
+		for _, entry := range entries {
+			objects[NormalizeDN(entry.DN)] = entryToLdapObject(entry)
+		}
+	}
+
+	for _, baseObjectDNs := range bases {
+		for _, objectDN := range baseObjectDNs {
+			if _, ok := objects[NormalizeDN(objectDN)]; !ok {
+				unresolved = append(unresolved, objectDN)
+			}
+		}
+	}
+
+	return objects, unresolved
+}
+
+// Returns the naming context of the domain the domain controller belongs to.
+func DefaultNamingContext(conn *ldap.Conn) (string, error) {
 
 	searchReq := ldap.NewSearchRequest(
-		objectDN,
+		"",
 		ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false,
 		"(objectClass=*)",
-		[]string{"objectSid"},
+		[]string{"defaultNamingContext"},
 		nil,
 	)
 
 	sr, err := conn.Search(searchReq)
 	if err != nil {
-		log.Fatalf("Search failed: %v", err)
+		return "", err
 	}
-
-	if len(sr.Entries) != 1 {
-		log.Fatalf("Expected 1 entry, got %d", len(sr.Entries))
+	if len(sr.Entries) == 0 || sr.Entries[0].GetAttributeValue("defaultNamingContext") == "" {
+		return "", fmt.Errorf("defaultNamingContext missing from the RootDSE")
 	}
 
-	entry := sr.Entries[0]
-	objectSid := sidBytesToString(entry.GetRawAttributeValue("objectSid"))
+	return sr.Entries[0].GetAttributeValue("defaultNamingContext"), nil
+}
+
+// Resolves user accounts by `sAMAccountName`, the result is keyed by lowercase account name.
+func ResolveAccountNames(conn *ldap.Conn, baseDN string, accountNames []string) (objects map[string]LdapObject) {
 
-	return objectSid, err
+	objects = make(map[string]LdapObject)
+
+	entries, err := searchBatch(conn, baseDN, "sAMAccountName", accountNames, USER_ACCOUNT_FILTER)
+	if err != nil {
+		log.Errorf("LDAP search failed on `%s`: %v", baseDN, err)
+	}
+	for _, entry := range entries {
+		object := entryToLdapObject(entry)
+		objects[strings.ToLower(object.SAMAccountName)] = object
+	}
+
+	return objects
 }
 
 func sidBytesToString(sidBytes []byte) string {
 
 	if len(sidBytes) < 8 {
-		return "<invalid SID>"
+		return INVALID_SID
 	}
 
 	revision := sidBytes[0]
 
 	numSubAuthorities := sidBytes[1]
+	if len(sidBytes) < 8+4*int(numSubAuthorities) {
+		return INVALID_SID
+	}
 
 	var authority uint64
 	for i := 0; i < 6; i++ {
@@ -119,3 +502,54 @@ func sidBytesToString(sidBytes []byte) string {
 
 	return sid
 }
+
+func guidBytesToString(guidBytes []byte) string {
+
+	if len(guidBytes) != 16 {
+		return ""
+	}
+
+	// The first three fields of the GUID are stored in little endian.
+	return fmt.Sprintf("%08X-%04X-%04X-%X-%X",
+		binary.LittleEndian.Uint32(guidBytes[0:4]),
+		binary.LittleEndian.Uint16(guidBytes[4:6]),
+		binary.LittleEndian.Uint16(guidBytes[6:8]),
+		guidBytes[8:10],
+		guidBytes[10:16])
+}
+
+// Resolves computer accounts by `dNSHostName` for FQDNs and by `sAMAccountName`
+// for short names, the result is keyed by lowercase host name.
+func ResolveComputers(conn *ldap.Conn, baseDN string, hostNames []string) (objects map[string]LdapObject) {
+
+	objects = make(map[string]LdapObject)
+
+	var fqdns, accountNames []string
+	for _, hostName := range hostNames {
+		if strings.Contains(hostName, ".") {
+			fqdns = append(fqdns, hostName)
+		} else {
+			accountNames = append(accountNames, hostName+"$")
+		}
+	}
+
+	entries, err := searchBatch(conn, baseDN, "dNSHostName", fqdns, COMPUTER_ACCOUNT_FILTER)
+	if err != nil {
+		log.Errorf("LDAP search failed on `%s`: %v", baseDN, err)
+	}
+	for _, entry := range entries {
+		object := entryToLdapObject(entry)
+		objects[strings.ToLower(object.DNSHostName)] = object
+	}
+
+	entries, err = searchBatch(conn, baseDN, "sAMAccountName", accountNames, COMPUTER_ACCOUNT_FILTER)
+	if err != nil {
+		log.Errorf("LDAP search failed on `%s`: %v", baseDN, err)
+	}
+	for _, entry := range entries {
+		object := entryToLdapObject(entry)
+		objects[strings.ToLower(strings.TrimSuffix(object.SAMAccountName, "$"))] = object
+	}
+
+	return objects
+}
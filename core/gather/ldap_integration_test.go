@@ -0,0 +1,118 @@
+//go:build integration
+
+package gather
+
+import (
+	"os"
+	"testing"
+)
+
+// These tests run against an OpenLDAP stand-in for the domain controller,
+// see `scripts/test-ldap.sh` to start one locally.
+
+func getEnv(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func initTestLdap(t *testing.T, bindMethod string, password string, startTLS bool) AHLdap {
+	host := os.Getenv("AH_TEST_LDAP_HOST")
+	if host == "" {
+		t.Skip("AH_TEST_LDAP_HOST is not set, skipping OpenLDAP tests.")
+	}
+	// NOTE: The certificate is always verified, TLS tests need the CA of the test server.
+	caFile := os.Getenv("AH_TEST_LDAP_CA_FILE")
+	if startTLS && caFile == "" {
+		t.Skip("AH_TEST_LDAP_CA_FILE is not set, skipping OpenLDAP TLS tests.")
+	}
+	bindDN := getEnv("AH_TEST_LDAP_BIND_DN", "cn=admin,dc=example,dc=org")
+	ldapObject, err := InitLdap(host, bindMethod, bindDN, password, "", "", "EXAMPLE",
+		false, startTLS, caFile, false)
+	if err != nil {
+		t.Fatalf("invalid test LDAP address: %v", err)
+	}
+	return ldapObject
+}
+
+func TestConnectSimpleBind(t *testing.T) {
+	ldapObject := initTestLdap(t, "", getEnv("AH_TEST_LDAP_PASSWORD", "admin"), false)
+	if ldapObject.BindMethod != BIND_SIMPLE {
+		t.Fatalf("expected default bind method `%s`, got `%s`", BIND_SIMPLE, ldapObject.BindMethod)
+	}
+
+	conn, err := Connect(ldapObject)
+	if err != nil {
+		t.Fatalf("simple bind failed: %v", err)
+	}
+	defer conn.Close()
+
+	result, err := conn.WhoAmI(nil)
+	if err != nil {
+		t.Fatalf("whoami failed: %v", err)
+	}
+	if result.AuthzID == "" {
+		t.Fatal("expected an authenticated identity")
+	}
+}
+
+func TestConnectInvalidPassword(t *testing.T) {
+	ldapObject := initTestLdap(t, BIND_SIMPLE, "invalid-password", false)
+
+	conn, err := Connect(ldapObject)
+	if err == nil {
+		conn.Close()
+		t.Fatal("expected the bind to fail with an invalid password")
+	}
+}
+
+func TestConnectUnsupportedBind(t *testing.T) {
+	// NOTE: OpenLDAP does not support NTLM, the failure must be returned instead of stopping the collection.
+	ldapObject := initTestLdap(t, BIND_NTLM, getEnv("AH_TEST_LDAP_PASSWORD", "admin"), false)
+
+	conn, err := Connect(ldapObject)
+	if err == nil {
+		conn.Close()
+		t.Fatal("expected the NTLM bind to fail on OpenLDAP")
+	}
+}
+
+func TestConnectKerberosWithoutCCache(t *testing.T) {
+	ldapObject := initTestLdap(t, BIND_KERBEROS, "", false)
+
+	conn, err := Connect(ldapObject)
+	if err == nil {
+		conn.Close()
+		t.Fatal("expected the Kerberos bind to fail without a ticket cache")
+	}
+}
+
+func TestConnectStartTLS(t *testing.T) {
+	ldapObject := initTestLdap(t, BIND_SIMPLE, getEnv("AH_TEST_LDAP_PASSWORD", "admin"), true)
+
+	conn, err := Connect(ldapObject)
+	if err != nil {
+		t.Fatalf("StartTLS bind failed: %v", err)
+	}
+	defer conn.Close()
+
+	state, ok := conn.TLSConnectionState()
+	if !ok {
+		t.Fatal("expected the connection to be upgraded to TLS")
+	}
+	if len(state.VerifiedChains) == 0 {
+		t.Fatal("expected the server certificate to be verified against the test CA")
+	}
+}
+
+func TestConnectStartTLSUntrustedCA(t *testing.T) {
+	ldapObject := initTestLdap(t, BIND_SIMPLE, getEnv("AH_TEST_LDAP_PASSWORD", "admin"), true)
+	ldapObject.CAFile = ""
+
+	conn, err := Connect(ldapObject)
+	if err == nil {
+		conn.Close()
+		t.Fatal("expected StartTLS to fail when the server certificate is not trusted")
+	}
+}
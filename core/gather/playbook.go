@@ -0,0 +1,292 @@
+package gather
+
+import (
+	"ansible-hound/core/ansible"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"gopkg.in/yaml.v3"
+)
+
+const BUILTIN_PREFIX = "ansible.builtin."
+const ROLES_DIR = "roles"
+
+// Keywords holding a list of tasks, in plays, blocks and roles.
+var TASK_LIST_KEYWORDS = []string{"tasks", "pre_tasks", "post_tasks", "handlers", "block", "rescue", "always"}
+
+// Analyzes the playbook of each Job Template from a local checkout of its project, checkouts are
+// given per project name or ID. Playbooks that cannot be read are skipped.
+func AnalyzePlaybooks(projectDirs map[string]string, projects map[int]*ansible.Project,
+	jobTemplates map[int]*ansible.JobTemplate) {
+
+	if len(projectDirs) == 0 {
+		return
+	}
+
+	log.Info("Analyzing Job Template playbooks.")
+	for _, jobTemplate := range jobTemplates {
+		if !HasAccessTo(projects, jobTemplate.Project) || jobTemplate.Playbook == "" {
+			continue
+		}
+		project := projects[jobTemplate.Project]
+		projectDir, ok := projectDirs[project.Name]
+		if !ok {
+			projectDir, ok = projectDirs[strconv.Itoa(project.ID)]
+		}
+		if !ok {
+			continue
+		}
+
+		analysis, err := AnalyzePlaybook(projectDir, jobTemplate.Playbook)
+		if err != nil {
+			log.Errorf("An error occured while analyzing the playbook of `%s`, skipping.", jobTemplate.Name)
+			log.Error(err)
+			continue
+		}
+		jobTemplate.PlaybookAnalysis = analysis
+	}
+}
+
+// playbookAnalyzer walks a playbook and the files it imports, each file and role is read once.
+type playbookAnalyzer struct {
+	projectDir string
+	analysis   *ansible.PlaybookAnalysis
+	visited    map[string]bool
+}
+
+func AnalyzePlaybook(projectDir string, playbook string) (analysis *ansible.PlaybookAnalysis, err error) {
+	analyzer := &playbookAnalyzer{
+		projectDir: projectDir,
+		analysis:   &ansible.PlaybookAnalysis{},
+		visited:    make(map[string]bool),
+	}
+	err = analyzer.playbook(filepath.Join(projectDir, playbook))
+	return analyzer.analysis, err
+}
+
+// Reads a YAML file of the project, paths outside of the project are refused.
+func (a *playbookAnalyzer) load(path string) (content any, err error) {
+	relative, err := filepath.Rel(a.projectDir, path)
+	if err != nil || strings.HasPrefix(relative, "..") {
+		return nil, fmt.Errorf("`%s` is outside of the project", path)
+	}
+	if a.visited[path] {
+		return nil, nil
+	}
+	a.visited[path] = true
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	err = yaml.Unmarshal(raw, &content)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse `%s`: %w", path, err)
+	}
+	a.analysis.Files = append(a.analysis.Files, filepath.ToSlash(relative))
+	return content, nil
+}
+
+func (a *playbookAnalyzer) playbook(path string) error {
+	content, err := a.load(path)
+	if err != nil {
+		return err
+	}
+	plays, _ := content.([]any)
+	for _, item := range plays {
+		play, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		if imported, ok := keyword(play, "import_playbook"); ok {
+			if importedPath, ok := imported.(string); ok && !strings.Contains(importedPath, "{{") {
+				err = a.playbook(filepath.Join(filepath.Dir(path), importedPath))
+				if err != nil {
+					log.Error(err)
+				}
+			}
+			continue
+		}
+
+		switch hosts := play["hosts"].(type) {
+		case string:
+			a.analysis.AddHosts(hosts)
+		case []any:
+			var patterns []string
+			for _, pattern := range hosts {
+				patterns = append(patterns, fmt.Sprint(pattern))
+			}
+			a.analysis.AddHosts(strings.Join(patterns, ","))
+		}
+
+		a.privileges(play)
+		a.lookups(play["vars"])
+
+		roles, _ := play["roles"].([]any)
+		for _, role := range roles {
+			switch typed := role.(type) {
+			case string:
+				a.role(typed, filepath.Dir(path))
+			case map[string]any:
+				name, _ := typed["role"].(string)
+				if name == "" {
+					name, _ = typed["name"].(string)
+				}
+				a.privileges(typed)
+				a.role(name, filepath.Dir(path))
+			}
+		}
+
+		for _, taskKeyword := range TASK_LIST_KEYWORDS {
+			a.tasks(play[taskKeyword], filepath.Dir(path), filepath.Dir(path))
+		}
+	}
+	return nil
+}
+
+// Walks a list of tasks, blocks are walked recursively and included task files and roles are
+// read. Task files are relative to the directory of the current file.
+func (a *playbookAnalyzer) tasks(content any, dir string, playbookDir string) {
+	tasks, _ := content.([]any)
+	for _, item := range tasks {
+		task, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		a.privileges(task)
+		if delegateTo, ok := task["delegate_to"].(string); ok {
+			a.analysis.AddDelegateTo(delegateTo)
+		}
+		a.lookups(task)
+
+		for _, taskKeyword := range TASK_LIST_KEYWORDS {
+			a.tasks(task[taskKeyword], dir, playbookDir)
+		}
+
+		for _, module := range []string{"include_tasks", "import_tasks"} {
+			if file, ok := keyword(task, module); ok {
+				if fileName := taskArgument(file, "file"); fileName != "" && !strings.Contains(fileName, "{{") {
+					content, err := a.load(filepath.Join(dir, fileName))
+					if err != nil {
+						log.Debug(err)
+						continue
+					}
+					a.tasks(content, dir, playbookDir)
+				}
+			}
+		}
+
+		for _, module := range []string{"include_role", "import_role"} {
+			if role, ok := keyword(task, module); ok {
+				a.role(taskArgument(role, "name"), playbookDir)
+			}
+		}
+	}
+}
+
+// Reads the tasks, handlers, variables and dependencies of a role, looked up next to the
+// playbook then at the root of the project. Roles of collections are not part of the project.
+func (a *playbookAnalyzer) role(name string, playbookDir string) {
+	if name == "" || strings.Contains(name, "{{") {
+		return
+	}
+	a.analysis.AddRole(name)
+	if strings.Count(name, ".") >= 2 {
+		return
+	}
+
+	var roleDir string
+	for _, candidate := range []string{filepath.Join(playbookDir, ROLES_DIR, name), filepath.Join(a.projectDir, ROLES_DIR, name)} {
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			roleDir = candidate
+			break
+		}
+	}
+	if roleDir == "" || a.visited[roleDir] {
+		return
+	}
+	a.visited[roleDir] = true
+
+	for _, file := range []string{"tasks", "handlers", "defaults", "vars", "meta"} {
+		for _, extension := range []string{".yml", ".yaml"} {
+			path := filepath.Join(roleDir, file, "main"+extension)
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			content, err := a.load(path)
+			if err != nil {
+				log.Debug(err)
+				continue
+			}
+			switch file {
+			case "tasks", "handlers":
+				a.tasks(content, filepath.Dir(path), playbookDir)
+			case "defaults", "vars":
+				a.lookups(content)
+			case "meta":
+				meta, _ := content.(map[string]any)
+				dependencies, _ := meta["dependencies"].([]any)
+				for _, dependency := range dependencies {
+					a.role(taskArgument(dependency, "role"), playbookDir)
+				}
+			}
+		}
+	}
+}
+
+func (a *playbookAnalyzer) privileges(content map[string]any) {
+	if ansible.IsTruthy(content["become"]) {
+		a.analysis.Become = true
+	}
+	if becomeUser, ok := content["become_user"].(string); ok {
+		a.analysis.AddBecomeUser(becomeUser)
+	}
+}
+
+// Scans every string of the content for secret lookups.
+func (a *playbookAnalyzer) lookups(content any) {
+	switch typed := content.(type) {
+	case string:
+		a.analysis.ScanLookups(typed)
+	case map[string]any:
+		for key, value := range typed {
+			// NOTE: Nested task lists are scanned when they are walked.
+			if !slices.Contains(TASK_LIST_KEYWORDS, key) {
+				a.lookups(value)
+			}
+		}
+	case []any:
+		for _, value := range typed {
+			a.lookups(value)
+		}
+	}
+}
+
+// Returns the value of a keyword or module, either short or prefixed by `ansible.builtin.`.
+func keyword(content map[string]any, name string) (value any, ok bool) {
+	if value, ok = content[name]; ok {
+		return value, ok
+	}
+	value, ok = content[BUILTIN_PREFIX+name]
+	return value, ok
+}
+
+// Returns an argument of a module, given either as free form (`include_tasks: file.yml`)
+// or as a map (`include_role: {name: role}`).
+func taskArgument(content any, name string) string {
+	switch typed := content.(type) {
+	case string:
+		return typed
+	case map[string]any:
+		value, _ := typed[name].(string)
+		return value
+	}
+	return ""
+}
@@ -0,0 +1,89 @@
+package gather
+
+import (
+	"ansible-hound/core/ansible"
+	"slices"
+	"testing"
+)
+
+// Fixture project of the playbook tests, `site.yml` imports `web.yml`, a malformed
+// playbook and a templated one, along with task files, roles and role dependencies.
+const TEST_PROJECT_DIR = "testdata/project"
+
+func TestAnalyzePlaybook(t *testing.T) {
+	analysis, err := AnalyzePlaybook(TEST_PROJECT_DIR, "site.yml")
+	if err != nil {
+		t.Fatalf("AnalyzePlaybook failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		values   []string
+		expected []string
+	}{
+		{"hosts", analysis.Hosts, []string{"web", "db,!decommissioned"}},
+		{"become users", analysis.BecomeUsers, []string{"postgres", "root"}},
+		{"delegate_to", analysis.DelegateTo, []string{"lb1.corp", "ldap1.corp", "localhost", "{{ backup_host }}"}},
+		{"lookups", analysis.Lookups, []string{"hashi_vault", "aws_secret", "env"}},
+		{"env lookups", analysis.EnvLookups, []string{"BACKUP_KEY"}},
+		{"roles", analysis.Roles, []string{"nginx", "common", "base", "acme.tools.monitoring"}},
+		{"files", analysis.Files, []string{
+			"site.yml", "web.yml", "roles/nginx/defaults/main.yml", "roles/common/tasks/main.yml",
+			"roles/common/tasks/users.yml", "roles/common/meta/main.yml", "roles/base/tasks/main.yml", "tasks/backup.yml",
+		}},
+	}
+	for _, test := range tests {
+		if !slices.Equal(test.values, test.expected) {
+			t.Errorf("unexpected %s %q, expected %q", test.name, test.values, test.expected)
+		}
+	}
+	if !analysis.Become {
+		t.Errorf("expected the playbook to escalate privileges")
+	}
+	if slices.Contains(analysis.DelegateTo, "broken.corp") {
+		t.Errorf("expected the malformed playbook to be skipped")
+	}
+}
+
+func TestAnalyzePlaybookErrors(t *testing.T) {
+	tests := []string{
+		"broken.yml",
+		"missing.yml",
+		"../project.yml",
+	}
+	for _, playbook := range tests {
+		if _, err := AnalyzePlaybook(TEST_PROJECT_DIR, playbook); err == nil {
+			t.Errorf("AnalyzePlaybook(%q) expected an error", playbook)
+		}
+	}
+}
+
+func TestAnalyzePlaybooks(t *testing.T) {
+	projects := map[int]*ansible.Project{
+		1: {Object: ansible.Object{ID: 1, Name: "Infra"}},
+		2: {Object: ansible.Object{ID: 2, Name: "Apps"}},
+	}
+	jobTemplates := map[int]*ansible.JobTemplate{
+		1: {Object: ansible.Object{ID: 1, Name: "Site"}, Project: 1, Playbook: "site.yml"},
+		2: {Object: ansible.Object{ID: 2, Name: "Broken"}, Project: 1, Playbook: "broken.yml"},
+		3: {Object: ansible.Object{ID: 3, Name: "Apps"}, Project: 2, Playbook: "site.yml"},
+		4: {Object: ansible.Object{ID: 4, Name: "By ID"}, Project: 2, Playbook: "web.yml"},
+	}
+
+	AnalyzePlaybooks(map[string]string{"Infra": TEST_PROJECT_DIR, "2": TEST_PROJECT_DIR}, projects, jobTemplates)
+
+	tests := []struct {
+		jobTemplate int
+		analyzed    bool
+	}{
+		{1, true},
+		{2, false},
+		{3, true},
+		{4, true},
+	}
+	for _, test := range tests {
+		if analyzed := jobTemplates[test.jobTemplate].PlaybookAnalysis != nil; analyzed != test.analyzed {
+			t.Errorf("`%s` analyzed = %t, expected %t", jobTemplates[test.jobTemplate].Name, analyzed, test.analyzed)
+		}
+	}
+}
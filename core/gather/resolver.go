@@ -0,0 +1,90 @@
+package gather
+
+import (
+	"github.com/go-ldap/ldap/v3"
+
+	"github.com/charmbracelet/log"
+)
+
+// ADResolver resolves Active Directory objects either live, through LDAP,
+// or offline, from data collected beforehand (EX: SharpHound).
+type ADResolver interface {
+	// Result is keyed by normalized DN, DNs that could not be found are returned as unresolved.
+	ResolveDNs(objectDNs []string) (objects map[string]LdapObject, unresolved []string)
+	// Result is keyed by lowercase account name, only user accounts are resolved.
+	// An empty base DN searches the domain of the domain controller, or every domain offline.
+	ResolveAccountNames(baseDN string, accountNames []string) (objects map[string]LdapObject)
+	// Host names are either FQDNs or short names, the result is keyed by lowercase host name.
+	ResolveComputers(hostNames []string) (objects map[string]LdapObject)
+	Close()
+}
+
+type LdapResolver struct {
+	Conn                 *ldap.Conn
+	defaultNamingContext string
+}
+
+func (r *LdapResolver) ResolveDNs(objectDNs []string) (map[string]LdapObject, []string) {
+	return ResolveDNs(r.Conn, objectDNs)
+}
+
+func (r *LdapResolver) ResolveAccountNames(baseDN string, accountNames []string) map[string]LdapObject {
+	if baseDN == "" {
+		baseDN = r.baseDN()
+		if baseDN == "" {
+			return make(map[string]LdapObject)
+		}
+	}
+	return ResolveAccountNames(r.Conn, baseDN, accountNames)
+}
+
+func (r *LdapResolver) ResolveComputers(hostNames []string) map[string]LdapObject {
+	baseDN := r.baseDN()
+	if baseDN == "" {
+		return make(map[string]LdapObject)
+	}
+	return ResolveComputers(r.Conn, baseDN, hostNames)
+}
+
+// Returns the domain of the domain controller, which is only read once.
+func (r *LdapResolver) baseDN() string {
+	if r.defaultNamingContext == "" {
+		defaultNamingContext, err := DefaultNamingContext(r.Conn)
+		if err != nil {
+			log.Errorf("Unable to read the domain of the domain controller: %v", err)
+			return ""
+		}
+		r.defaultNamingContext = defaultNamingContext
+	}
+	return r.defaultNamingContext
+}
+
+func (r *LdapResolver) Close() {
+	r.Conn.Close()
+}
+
+func InitADResolver(ldapObject AHLdap, sharpHoundPaths []string) ADResolver {
+
+	if len(sharpHoundPaths) > 0 {
+		log.Info("Loading SharpHound data.")
+		resolver, err := LoadSharpHound(sharpHoundPaths)
+		if err != nil {
+			log.Error("Unable to load SharpHound data, skipping linking Active Directory.")
+			log.Error(err)
+			return nil
+		}
+		return resolver
+	}
+
+	if (ldapObject != AHLdap{}) {
+		conn, err := Connect(ldapObject)
+		if err != nil {
+			log.Error("Connection failed, skipping linking Active Directory.")
+			log.Error(err)
+			return nil
+		}
+		return &LdapResolver{Conn: conn}
+	}
+
+	return nil
+}
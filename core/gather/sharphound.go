@@ -0,0 +1,207 @@
+package gather
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+const SHARPHOUND_USERS = "users"
+const SHARPHOUND_COMPUTERS = "computers"
+
+type SharpHoundObject struct {
+	ObjectIdentifier string `json:"ObjectIdentifier"`
+	Properties       struct {
+		Name              string `json:"name"`
+		Domain            string `json:"domain"`
+		DistinguishedName string `json:"distinguishedname"`
+		SAMAccountName    string `json:"samaccountname"`
+		ObjectGUID        string `json:"objectguid"`
+	} `json:"Properties"`
+}
+
+type SharpHoundFile struct {
+	Data []SharpHoundObject `json:"data"`
+	Meta struct {
+		Type    string `json:"type"`
+		Count   int    `json:"count"`
+		Version int    `json:"version"`
+	} `json:"meta"`
+}
+
+// SharpHoundResolver resolves Active Directory objects offline from SharpHound output files.
+type SharpHoundResolver struct {
+	ByDN             map[string]LdapObject
+	UsersByAccount   map[string][]LdapObject
+	accountsByDomain map[string]map[string]LdapObject
+	ComputersByName  map[string][]LdapObject
+}
+
+func (o *SharpHoundObject) toLdapObject() LdapObject {
+	return LdapObject{
+		DN:             o.Properties.DistinguishedName,
+		ObjectSid:      o.ObjectIdentifier,
+		ObjectGUID:     o.Properties.ObjectGUID,
+		SAMAccountName: o.Properties.SAMAccountName,
+	}
+}
+
+// Loads SharpHound output, each path can either be a JSON file or a zip archive.
+func LoadSharpHound(paths []string) (*SharpHoundResolver, error) {
+
+	resolver := &SharpHoundResolver{
+		ByDN:             make(map[string]LdapObject),
+		UsersByAccount:   make(map[string][]LdapObject),
+		accountsByDomain: make(map[string]map[string]LdapObject),
+		ComputersByName:  make(map[string][]LdapObject),
+	}
+
+	for _, path := range paths {
+		var err error
+		if strings.EqualFold(filepath.Ext(path), ".zip") {
+			err = resolver.loadZip(path)
+		} else {
+			var content []byte
+			content, err = os.ReadFile(path)
+			if err == nil {
+				err = resolver.load(content)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("unable to load `%s`: %w", path, err)
+		}
+	}
+
+	log.Infof("Loaded %d Active Directory objects from SharpHound data.", len(resolver.ByDN))
+
+	return resolver, nil
+}
+
+func (r *SharpHoundResolver) loadZip(path string) error {
+
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	for _, file := range archive.File {
+		if !strings.EqualFold(filepath.Ext(file.Name), ".json") {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return err
+		}
+		content, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return err
+		}
+		err = r.load(content)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *SharpHoundResolver) load(content []byte) error {
+
+	// NOTE: SharpHound writes its files with a UTF-8 BOM.
+	content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+
+	var sharpHoundFile SharpHoundFile
+	err := json.Unmarshal(content, &sharpHoundFile)
+	if err != nil {
+		return err
+	}
+
+	for _, object := range sharpHoundFile.Data {
+		ldapObject := object.toLdapObject()
+		if ldapObject.DN != "" {
+			r.ByDN[NormalizeDN(ldapObject.DN)] = ldapObject
+		}
+		if sharpHoundFile.Meta.Type == SHARPHOUND_USERS && ldapObject.SAMAccountName != "" {
+			accountName := strings.ToLower(ldapObject.SAMAccountName)
+			r.UsersByAccount[accountName] = append(r.UsersByAccount[accountName], ldapObject)
+			domain := strings.ToUpper(object.Properties.Domain)
+			if r.accountsByDomain[domain] == nil {
+				r.accountsByDomain[domain] = make(map[string]LdapObject)
+			}
+			r.accountsByDomain[domain][accountName] = ldapObject
+		}
+		// NOTE: SharpHound names computers by FQDN, they are indexed by short name as well.
+		if sharpHoundFile.Meta.Type == SHARPHOUND_COMPUTERS && object.Properties.Name != "" {
+			ldapObject.DNSHostName = object.Properties.Name
+			fqdn := strings.ToLower(object.Properties.Name)
+			shortName, _, _ := strings.Cut(fqdn, ".")
+			r.ComputersByName[fqdn] = append(r.ComputersByName[fqdn], ldapObject)
+			if shortName != fqdn {
+				r.ComputersByName[shortName] = append(r.ComputersByName[shortName], ldapObject)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *SharpHoundResolver) ResolveDNs(objectDNs []string) (objects map[string]LdapObject, unresolved []string) {
+
+	objects = make(map[string]LdapObject)
+	for _, objectDN := range objectDNs {
+		normalizedDN := NormalizeDN(objectDN)
+		if object, ok := r.ByDN[normalizedDN]; ok {
+			objects[normalizedDN] = object
+		} else {
+			unresolved = append(unresolved, objectDN)
+		}
+	}
+
+	return objects, unresolved
+}
+
+func (r *SharpHoundResolver) ResolveAccountNames(baseDN string, accountNames []string) (objects map[string]LdapObject) {
+
+	objects = make(map[string]LdapObject)
+	domain := DomainFromBaseDN(baseDN)
+	for _, accountName := range accountNames {
+		accountName = strings.ToLower(accountName)
+		if domain != "" {
+			if object, ok := r.accountsByDomain[domain][accountName]; ok {
+				objects[accountName] = object
+			}
+			continue
+		}
+		// NOTE: Without a domain, the account name is only trusted if it is unique across domains.
+		if candidates := r.UsersByAccount[accountName]; len(candidates) == 1 {
+			objects[accountName] = candidates[0]
+		}
+	}
+
+	return objects
+}
+
+func (r *SharpHoundResolver) ResolveComputers(hostNames []string) (objects map[string]LdapObject) {
+
+	objects = make(map[string]LdapObject)
+	for _, hostName := range hostNames {
+		hostName = strings.ToLower(hostName)
+		// NOTE: Short names are only trusted if they are unique across domains.
+		if candidates := r.ComputersByName[hostName]; len(candidates) == 1 {
+			objects[hostName] = candidates[0]
+		}
+	}
+
+	return objects
+}
+
+func (r *SharpHoundResolver) Close() {}
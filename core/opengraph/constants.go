@@ -1,6 +1,9 @@
 package opengraph
 
+import "ansible-hound/core/ansible"
+
 const LDAP_VALUE = "ldap"
+const SOCIAL_VALUE = "social"
 
 const GIT_SCM_TYPE = "git"
 const DOT_GIT_SCM_TYPE = "." + GIT_SCM_TYPE
@@ -10,7 +13,28 @@ const MATCH_BY_NAME = "name"
 
 const ACTIVE_DIRECTORY_BASE = "Base"
 const ANSIBLE_BASE = "AnsibleBase"
-const GITHUB_BASE = "GHBase"
+const AZURE_USER = "AZUser"
+
+const AZURE_KIND_PREFIX = "AZ"
+
+// Node kinds of the cloud virtual machines, keyed by inventory source type.
+var DEFAULT_CLOUD_VM_KINDS = map[string]string{
+	"azure_rm": "AZVM",
+	"ec2":      "AWSEC2Instance",
+	"gce":      "GCPComputeInstance",
+}
+
+// Node kinds of the cloud principals, keyed by the type of identity held by a credential.
+var DEFAULT_CLOUD_PRINCIPAL_KINDS = map[string]string{
+	ansible.AWS_ACCESS_KEY_IDENTITY:          "AWSAccessKey",
+	ansible.AZURE_SERVICE_PRINCIPAL_IDENTITY: "AZServicePrincipal",
+	ansible.AZURE_USER_IDENTITY:              AZURE_USER,
+	ansible.GCE_SERVICE_ACCOUNT_IDENTITY:     "GCPServiceAccount",
+	ansible.OPENSTACK_USER_IDENTITY:          "OpenStackUser",
+}
 
 const CREDENTIAL_USERNAME = "username"
 const CREDENTIAL_KIND = "scm"
+
+const MACHINE_CREDENTIAL_KIND = "ssh"
+const NETWORK_CREDENTIAL_KIND = "net"
@@ -3,7 +3,7 @@ package opengraph
 import (
 	"ansible-hound/core/ansible"
 	"ansible-hound/core/gather"
-	"path"
+	"slices"
 	"strings"
 
 	"github.com/Ramoreik/gopengraph"
@@ -69,10 +69,21 @@ func LinkOrganization(graph *gopengraph.OpenGraph, instanceOID string,
 		}
 	}
 
+	log.Info("Linking Organizations and Galaxy Credentials.")
+	edgeKind = "ATUsesGalaxyCredential"
+	for _, organization := range organizations {
+		for order, galaxyCredential := range organization.GalaxyCredentials {
+			edge := GenerateEdge(edgeKind, organization.OID, galaxyCredential.OID)
+			edge.SetProperty("order", order)
+			AddEdge(graph, edge)
+		}
+	}
+
 }
 
 func LinkInventory(graph *gopengraph.OpenGraph, inventories map[int]*ansible.Inventory,
-	hosts map[int]*ansible.Host, groups map[int]*ansible.Group) {
+	hosts map[int]*ansible.Host, groups map[int]*ansible.Group,
+	inventorySources map[int]*ansible.InventorySource) {
 
 	log.Info("Linking Inventories and Hosts.")
 	edgeKind := "ATContains"
@@ -83,6 +94,28 @@ func LinkInventory(graph *gopengraph.OpenGraph, inventories map[int]*ansible.Inv
 		}
 	}
 
+	log.Info("Linking Smart and Constructed Inventories and Hosts.")
+	edgeKind = "ATContains"
+	for _, inventory := range inventories {
+		for _, host := range inventory.Hosts {
+			if gather.HasAccessTo(hosts, host.ID) {
+				edge := GenerateEdge(edgeKind, inventory.OID, hosts[host.ID].OID)
+				AddEdge(graph, edge)
+			}
+		}
+	}
+
+	log.Info("Linking Constructed Inventories and Input Inventories.")
+	edgeKind = "ATDerivesFrom"
+	for _, inventory := range inventories {
+		for _, inputInventory := range inventory.InputInventories {
+			if gather.HasAccessTo(inventories, inputInventory.ID) {
+				edge := GenerateEdge(edgeKind, inventory.OID, inventories[inputInventory.ID].OID)
+				AddEdge(graph, edge)
+			}
+		}
+	}
+
 	log.Info("Linking Inventories and Groups.")
 	edgeKind = "ATContains"
 	for _, group := range groups {
@@ -92,6 +125,15 @@ func LinkInventory(graph *gopengraph.OpenGraph, inventories map[int]*ansible.Inv
 		}
 	}
 
+	log.Info("Linking Inventories and Inventory Sources.")
+	edgeKind = "ATContains"
+	for _, inventorySource := range inventorySources {
+		if gather.HasAccessTo(inventories, inventorySource.Inventory) {
+			edge := GenerateEdge(edgeKind, inventories[inventorySource.Inventory].OID, inventorySource.OID)
+			AddEdge(graph, edge)
+		}
+	}
+
 	log.Info("Linking Groups and Hosts.")
 	edgeKind = "ATContains"
 	for _, group := range groups {
@@ -103,11 +145,23 @@ func LinkInventory(graph *gopengraph.OpenGraph, inventories map[int]*ansible.Inv
 		}
 	}
 
+	log.Info("Linking Groups and their Children.")
+	edgeKind = "ATContains"
+	for _, group := range groups {
+		for _, child := range group.Children {
+			if gather.HasAccessTo(groups, child.ID) {
+				edge := GenerateEdge(edgeKind, group.OID, groups[child.ID].OID)
+				AddEdge(graph, edge)
+			}
+		}
+	}
+
 }
 
 func LinkWorkflowJobTemplates(graph *gopengraph.OpenGraph, workflowJobTemplates map[int]*ansible.WorkflowJobTemplate,
 	workflowJobTemplateNodes map[int]*ansible.WorkflowJobTemplateNode,
-	jobTemplates map[int]*ansible.JobTemplate, inventories map[int]*ansible.Inventory) {
+	jobTemplates map[int]*ansible.JobTemplate, inventories map[int]*ansible.Inventory,
+	credentials map[int]*ansible.Credential) {
 
 	log.Info("Linking Workflow Job Templates and Workflow Job Template Nodes.")
 	edgeKind := "ATContains"
@@ -136,6 +190,15 @@ func LinkWorkflowJobTemplates(graph *gopengraph.OpenGraph, workflowJobTemplates
 		}
 	}
 
+	log.Info("Linking Workflow Job Template and Webhook Credentials.")
+	edgeKind = "ATWebhookCredential"
+	for _, workflowJobTemplate := range workflowJobTemplates {
+		if gather.HasAccessTo(credentials, workflowJobTemplate.WebhookCredential) {
+			edge := GenerateEdge(edgeKind, workflowJobTemplate.OID, credentials[workflowJobTemplate.WebhookCredential].OID)
+			AddEdge(graph, edge)
+		}
+	}
+
 }
 
 func LinkJobTemplates(graph *gopengraph.OpenGraph, jobTemplates map[int]*ansible.JobTemplate,
@@ -179,6 +242,15 @@ func LinkJobTemplates(graph *gopengraph.OpenGraph, jobTemplates map[int]*ansible
 		}
 	}
 
+	log.Info("Linking Job Templates and Webhook Credentials.")
+	edgeKind = "ATWebhookCredential"
+	for _, jobTemplate := range jobTemplates {
+		if gather.HasAccessTo(credentials, jobTemplate.WebhookCredential) {
+			edge := GenerateEdge(edgeKind, jobTemplate.OID, credentials[jobTemplate.WebhookCredential].OID)
+			AddEdge(graph, edge)
+		}
+	}
+
 	log.Info("Linking Credentials to Credential Type.")
 	edgeKind = "ATUsesType"
 	for _, credential := range credentials {
@@ -199,6 +271,42 @@ func LinkJobTemplates(graph *gopengraph.OpenGraph, jobTemplates map[int]*ansible
 
 }
 
+// Creates an `ATRetrievesSecretFrom` edge between a credential and each lookup credential filling
+// its inputs. A lookup credential can fill several inputs, they are grouped on a single edge.
+func LinkCredentialInputSources(graph *gopengraph.OpenGraph, credentials map[int]*ansible.Credential,
+	credentialInputSources map[int]*ansible.CredentialInputSource) {
+
+	log.Info("Linking Credentials and their Input Sources.")
+	edgeKind := "ATRetrievesSecretFrom"
+
+	type lookup struct{ target, source int }
+	lookups := make(map[lookup][]*ansible.CredentialInputSource)
+	for _, credentialInputSource := range credentialInputSources {
+		if gather.HasAccessTo(credentials, credentialInputSource.TargetCredential) &&
+			gather.HasAccessTo(credentials, credentialInputSource.SourceCredential) {
+			key := lookup{credentialInputSource.TargetCredential, credentialInputSource.SourceCredential}
+			lookups[key] = append(lookups[key], credentialInputSource)
+		}
+	}
+
+	for key, inputSources := range lookups {
+		slices.SortFunc(inputSources, func(a *ansible.CredentialInputSource, b *ansible.CredentialInputSource) int {
+			return strings.Compare(a.InputFieldName, b.InputFieldName)
+		})
+
+		edge := GenerateEdge(edgeKind, credentials[key.target].OID, credentials[key.source].OID)
+		var inputFieldNames []string
+		for _, inputSource := range inputSources {
+			inputFieldNames = append(inputFieldNames, inputSource.InputFieldName)
+			for metadataKey, value := range inputSource.MetadataProperties() {
+				edge.SetProperty("metadata_"+inputSource.InputFieldName+"_"+metadataKey, value)
+			}
+		}
+		edge.SetProperty("input_field_names", strings.Join(inputFieldNames, ", "))
+		AddEdge(graph, edge)
+	}
+}
+
 func LinkUserRoles(graph *gopengraph.OpenGraph, users map[int]*ansible.User,
 	organizations map[int]*ansible.Organization, inventories map[int]*ansible.Inventory,
 	teams map[int]*ansible.Team, credentials map[int]*ansible.Credential,
@@ -379,75 +487,525 @@ func LinkAdministrativeRights(graph *gopengraph.OpenGraph, users map[int]*ansibl
 
 }
 
-func LinkAD(graph *gopengraph.OpenGraph, ldap gather.AHLdap, users map[int]*ansible.User) {
+func LinkAD(graph *gopengraph.OpenGraph, adResolver gather.ADResolver, users map[int]*ansible.User) {
 
-	if (ldap != gather.AHLdap{}) {
+	if adResolver != nil {
 
 		log.Info("Linking Ansible and LDAP Users.")
 		edgeKind := "SyncedToATUser"
 
-		conn, err := gather.Connect(ldap)
+		var ldapDns []string
+		for _, user := range users {
+			if user.ExternalAccount == LDAP_VALUE && user.LdapDn != "" {
+				ldapDns = append(ldapDns, user.LdapDn)
+			}
+		}
 
-		if err != nil {
-			log.Fatal("Connection failed:", err)
+		objects, _ := adResolver.ResolveDNs(ldapDns)
+
+		// NOTE: Objects that were moved since the last login of the user are not found by DN,
+		// the username is used as a fallback since it usually is the `sAMAccountName`.
+		movedUsers := make(map[string][]string)
+		for _, user := range users {
+			if user.ExternalAccount == LDAP_VALUE && user.LdapDn != "" {
+				if _, ok := objects[gather.NormalizeDN(user.LdapDn)]; !ok {
+					baseDN := gather.BaseDN(user.LdapDn)
+					movedUsers[baseDN] = append(movedUsers[baseDN], user.Username)
+				}
+			}
+		}
+		accounts := make(map[string]gather.LdapObject)
+		for baseDN, accountNames := range movedUsers {
+			if baseDN == "" {
+				continue
+			}
+			for accountName, object := range adResolver.ResolveAccountNames(baseDN, accountNames) {
+				accounts[accountName] = object
+			}
 		}
-		defer conn.Close()
 
+		var unresolved []string
 		for _, user := range users {
-			if user.ExternalAccount == LDAP_VALUE {
-				if user.LdapDn != "" {
-					ldap_dn := user.LdapDn
-					objectSid, _ := gather.Search(conn, ldap_dn)
-					edge := GenerateEdgeCustom(edgeKind, objectSid, user.OID, MATCH_BY_ID, MATCH_BY_ID, ACTIVE_DIRECTORY_BASE, ANSIBLE_BASE)
-					graph.AddEdgeWithoutValidation(edge)
+			if user.ExternalAccount == LDAP_VALUE && user.LdapDn != "" {
+				object, ok := objects[gather.NormalizeDN(user.LdapDn)]
+				if !ok {
+					object, ok = accounts[strings.ToLower(user.Username)]
+				}
+				if !ok || object.ObjectSid == "" {
+					unresolved = append(unresolved, user.Username)
+					continue
+				}
+
+				if userNode := graph.GetNode(user.OID); userNode != nil {
+					userNode.SetProperty("ad_object_sid", object.ObjectSid)
+					userNode.SetProperty("ad_object_guid", object.ObjectGUID)
+					userNode.SetProperty("ad_samaccountname", object.SAMAccountName)
 				}
+
+				edge := GenerateEdgeCustom(edgeKind, object.ObjectSid, user.OID, MATCH_BY_ID, MATCH_BY_ID, ACTIVE_DIRECTORY_BASE, ANSIBLE_BASE)
+				graph.AddEdgeWithoutValidation(edge)
 			}
 		}
+
+		if len(unresolved) > 0 {
+			log.Warnf("Unable to resolve %d LDAP User(s) in Active Directory: %s",
+				len(unresolved), strings.Join(unresolved, ", "))
+		}
 	} else {
-		log.Warn("Skipping linking LDAP Users since the user used for authentication is a local user")
+		log.Warn("Skipping linking LDAP Users since neither a domain controller nor SharpHound data was provided")
 	}
 }
 
-func LinkGitHub(graph *gopengraph.OpenGraph, github bool, projects map[int]*ansible.Project, credentials map[int]*ansible.Credential) {
+func LinkADGroups(graph *gopengraph.OpenGraph, adResolver gather.ADResolver, settings *ansible.Settings,
+	organizations map[int]*ansible.Organization, teams map[int]*ansible.Team) {
+
+	if adResolver == nil {
+		log.Warn("Skipping linking LDAP Groups since neither a domain controller nor SharpHound data was provided")
+		return
+	}
+
+	if settings == nil || len(settings.Ldap) == 0 {
+		log.Warn("Skipping linking LDAP Groups since no LDAP organization or team map could be read")
+		return
+	}
 
-	if github {
+	log.Info("Linking LDAP Groups and Ansible Organizations and Teams.")
+	edgeKind := "ADGroupGrantsATRole"
 
-		log.Info("Linking Ansible and GitHub.")
-		log.Warn("Do not forget to upload GitHub graphing on BloodHound to leverage.")
+	var groupDns []string
+	for _, ldapSettings := range settings.Ldap {
+		for _, grant := range ldapSettings.RoleGrants() {
+			if grant.GroupDn != "" {
+				groupDns = append(groupDns, grant.GroupDn)
+			}
+		}
+	}
 
-		if projects != nil {
+	objects, unresolved := adResolver.ResolveDNs(groupDns)
+	if len(unresolved) > 0 {
+		log.Warnf("Unable to resolve %d LDAP Group(s) in Active Directory: %s",
+			len(unresolved), strings.Join(unresolved, "; "))
+	}
 
-			log.Info("Linking Ansible projects and GitHub Repositories.")
-			edgeKind := "ATHasSourceControlUrl"
+	for _, ldapSettings := range settings.Ldap {
+		for _, grant := range ldapSettings.RoleGrants() {
 
-			for _, project := range projects {
-				if project.ScmType == GIT_SCM_TYPE {
-					if project.ScmUrl != "" {
-						scmUrl := project.ScmUrl
-						repositoryName := strings.TrimSuffix(path.Base(scmUrl), DOT_GIT_SCM_TYPE)
-						edge := GenerateEdgeCustom(edgeKind, project.OID, repositoryName, MATCH_BY_ID, MATCH_BY_NAME, ANSIBLE_BASE, GITHUB_BASE)
-						graph.AddEdgeWithoutValidation(edge)
-					}
+			var resourceOID string
+			switch grant.ResourceType {
+			case "organization":
+				if organization := findOrganizationByName(organizations, grant.ResourceName); organization != nil {
+					resourceOID = organization.OID
+				}
+			case "team":
+				if team := findTeamByName(organizations, teams, grant.ResourceName, grant.Organization); team != nil {
+					resourceOID = team.OID
 				}
 			}
+			if resourceOID == "" {
+				log.Debugf("Unable to find %s `%s` from the LDAP map, skipping.", grant.ResourceType, grant.ResourceName)
+				continue
+			}
+
+			// NOTE: A stale or misspelled group DN only skips its own grant.
+			object, ok := objects[gather.NormalizeDN(grant.GroupDn)]
+			if !ok || object.ObjectSid == "" {
+				log.Debugf("Unable to resolve the LDAP Group `%s` granting %s on `%s`, skipping.", grant.GroupDn, grant.Role, grant.ResourceName)
+				continue
+			}
+
+			edge := GenerateEdgeCustom(edgeKind, object.ObjectSid, resourceOID, MATCH_BY_ID, MATCH_BY_ID, ACTIVE_DIRECTORY_BASE, ANSIBLE_BASE)
+			edge.SetProperty("role", grant.Role)
+			edge.SetProperty("group_dn", grant.GroupDn)
+			edge.SetProperty("ldap_server", ldapSettings.ServerUri)
+			edge.SetProperty("remove", grant.Remove)
+			graph.AddEdgeWithoutValidation(edge)
+		}
+	}
+}
+
+func findOrganizationByName(organizations map[int]*ansible.Organization, name string) *ansible.Organization {
+	for _, organization := range organizations {
+		if organization.Name == name {
+			return organization
+		}
+	}
+	return nil
+}
+
+func findTeamByName(organizations map[int]*ansible.Organization, teams map[int]*ansible.Team,
+	name string, organizationName string) *ansible.Team {
+	for _, team := range teams {
+		if team.Name != name {
+			continue
 		}
+		// NOTE: Team names are only unique within an Organization.
+		if organizationName != "" && gather.HasAccessTo(organizations, team.Organization) &&
+			organizations[team.Organization].Name != organizationName {
+			continue
+		}
+		return team
+	}
+	return nil
+}
+
+// Splits `DOMAIN\user` and `user@domain` usernames, the account is empty
+// when the username is not qualified with a domain.
+func parseDomainAccount(username string) (account string, domain string, isUPN bool) {
+	username = strings.TrimSpace(username)
+	if domain, account, ok := strings.Cut(username, `\`); ok && domain != "" && account != "" {
+		return account, domain, false
+	}
+	if i := strings.LastIndex(username, "@"); i > 0 && i < len(username)-1 {
+		return username[:i], username[i+1:], true
+	}
+	return "", "", false
+}
 
-		if credentials != nil {
+func LinkCredentialsAD(graph *gopengraph.OpenGraph, adResolver gather.ADResolver,
+	credentials map[int]*ansible.Credential) {
 
-			log.Info("Linking Ansible credentials and GitHub users.")
-			edgeKind := "ATIsCredentialOf"
+	log.Info("Linking Ansible Machine/Network credentials and Active Directory users.")
+	edgeKind := "ATIsCredentialOf"
 
-			for _, credential := range credentials {
-				if credential.Kind == CREDENTIAL_KIND {
-					username := credential.Inputs[CREDENTIAL_USERNAME]
-					if username != nil && username != "" {
-						edge := GenerateEdgeCustom(edgeKind, credential.OID, username.(string), MATCH_BY_ID, MATCH_BY_NAME, ANSIBLE_BASE, GITHUB_BASE)
-						graph.AddEdgeWithoutValidation(edge)
-					}
+	// NOTE: UPNs are searched in the domain of their suffix, down-level names
+	// are searched in the domain of the domain controller since NetBIOS names cannot be mapped.
+	accountNames := make(map[string][]string)
+	for _, credential := range credentials {
+		if credential.Kind != MACHINE_CREDENTIAL_KIND && credential.Kind != NETWORK_CREDENTIAL_KIND {
+			continue
+		}
+		username, _ := credential.Inputs[CREDENTIAL_USERNAME].(string)
+		account, domain, isUPN := parseDomainAccount(username)
+		if account == "" {
+			continue
+		}
+		var baseDN string
+		if isUPN {
+			baseDN = gather.BaseDNFromDomain(domain)
+		}
+		accountNames[baseDN] = append(accountNames[baseDN], account)
+	}
+
+	resolved := make(map[string]map[string]gather.LdapObject)
+	if adResolver != nil {
+		for baseDN, names := range accountNames {
+			resolved[baseDN] = adResolver.ResolveAccountNames(baseDN, names)
+		}
+	}
+
+	var unresolved []string
+	for _, credential := range credentials {
+		if credential.Kind != MACHINE_CREDENTIAL_KIND && credential.Kind != NETWORK_CREDENTIAL_KIND {
+			continue
+		}
+		username, _ := credential.Inputs[CREDENTIAL_USERNAME].(string)
+		account, domain, isUPN := parseDomainAccount(username)
+		if account == "" {
+			continue
+		}
+		var baseDN string
+		if isUPN {
+			baseDN = gather.BaseDNFromDomain(domain)
+		}
+
+		var credentialEdge *edge.Edge
+		if object, ok := resolved[baseDN][strings.ToLower(account)]; ok && object.ObjectSid != "" {
+			credentialEdge = GenerateEdgeCustom(edgeKind, credential.OID, object.ObjectSid, MATCH_BY_ID, MATCH_BY_ID, ANSIBLE_BASE, ACTIVE_DIRECTORY_BASE)
+		} else if isUPN {
+			// NOTE: BloodHound names users `SAMACCOUNTNAME@DOMAIN`, which matches the UPN in most environments.
+			credentialEdge = GenerateEdgeCustom(edgeKind, credential.OID, strings.ToUpper(account+"@"+domain), MATCH_BY_ID, MATCH_BY_NAME, ANSIBLE_BASE, ACTIVE_DIRECTORY_BASE)
+		} else {
+			unresolved = append(unresolved, username)
+			continue
+		}
+		credentialEdge.SetProperty("username", username)
+		graph.AddEdgeWithoutValidation(credentialEdge)
+	}
+
+	if len(unresolved) > 0 {
+		log.Warnf("Unable to resolve %d down-level credential username(s) in Active Directory: %s",
+			len(unresolved), strings.Join(unresolved, ", "))
+	}
+}
+
+func LinkHostsAD(graph *gopengraph.OpenGraph, adResolver gather.ADResolver,
+	hosts map[int]*ansible.Host) {
+
+	log.Info("Linking Ansible Hosts and Active Directory computers.")
+	edgeKind := "ATHostIsComputer"
+
+	var hostNames []string
+	for _, host := range hosts {
+		hostNames = append(hostNames, host.HostNames()...)
+	}
+
+	computers := make(map[string]gather.LdapObject)
+	if adResolver != nil && len(hostNames) > 0 {
+		computers = adResolver.ResolveComputers(hostNames)
+	}
+
+	for _, host := range hosts {
+
+		var hostEdge *edge.Edge
+		for _, hostName := range host.HostNames() {
+			if computer, ok := computers[hostName]; ok && computer.ObjectSid != "" {
+				hostEdge = GenerateEdgeCustom(edgeKind, host.OID, computer.ObjectSid, MATCH_BY_ID, MATCH_BY_ID, ANSIBLE_BASE, ACTIVE_DIRECTORY_BASE)
+				hostEdge.SetProperty("host_name", hostName)
+				break
+			}
+		}
+
+		// NOTE: BloodHound names computers by their uppercase FQDN, which is used
+		// when the computer could not be resolved.
+		if hostEdge == nil {
+			for _, hostName := range host.HostNames() {
+				if strings.Contains(hostName, ".") {
+					hostEdge = GenerateEdgeCustom(edgeKind, host.OID, strings.ToUpper(hostName), MATCH_BY_ID, MATCH_BY_NAME, ANSIBLE_BASE, ACTIVE_DIRECTORY_BASE)
+					hostEdge.SetProperty("host_name", hostName)
+					break
+				}
+			}
+		}
+
+		if hostEdge != nil {
+			graph.AddEdgeWithoutValidation(hostEdge)
+		}
+	}
+}
+
+func LinkCloudHosts(graph *gopengraph.OpenGraph, cloudVMKinds map[string]string,
+	hosts map[int]*ansible.Host) {
+
+	log.Info("Linking Ansible Hosts and cloud virtual machines.")
+	edgeKind := "ATHostIsVM"
+
+	for _, host := range hosts {
+		if host.InstanceId == "" || host.InventorySource == "" {
+			continue
+		}
+		targetKind := cloudVMKinds[host.InventorySource]
+		if targetKind == "" {
+			continue
+		}
+
+		instanceId := host.InstanceId
+		// NOTE: AzureHound stores resource ids in uppercase.
+		if strings.HasPrefix(targetKind, AZURE_KIND_PREFIX) {
+			instanceId = strings.ToUpper(instanceId)
+		}
+
+		edge := GenerateEdgeCustom(edgeKind, host.OID, instanceId, MATCH_BY_ID, MATCH_BY_ID, ANSIBLE_BASE, targetKind)
+		edge.SetProperty("inventory_source", host.InventorySource)
+		graph.AddEdgeWithoutValidation(edge)
+	}
+}
+
+func LinkCloudCredentials(graph *gopengraph.OpenGraph, cloudPrincipalKinds map[string]string,
+	servicePrincipals map[string]string, credentials map[int]*ansible.Credential) {
+
+	log.Info("Linking Ansible cloud credentials and cloud principals.")
+	edgeKind := "ATIsCredentialOf"
+
+	var unresolved []string
+	for _, credential := range credentials {
+		identity, ok := credential.CloudIdentity()
+		if !ok {
+			continue
+		}
+		targetKind := cloudPrincipalKinds[identity.Type]
+		if targetKind == "" {
+			continue
+		}
+
+		var credentialEdge *edge.Edge
+		switch identity.Type {
+		case ansible.AZURE_SERVICE_PRINCIPAL_IDENTITY:
+			// NOTE: Service principals are identified by their object id, which is
+			// recovered from their application id using AzureHound data.
+			objectId, ok := servicePrincipals[strings.ToLower(identity.Identifier)]
+			if !ok {
+				unresolved = append(unresolved, identity.Identifier)
+				continue
+			}
+			credentialEdge = GenerateEdgeCustom(edgeKind, credential.OID, objectId, MATCH_BY_ID, MATCH_BY_ID, ANSIBLE_BASE, targetKind)
+		case ansible.AZURE_USER_IDENTITY:
+			credentialEdge = GenerateEdgeCustom(edgeKind, credential.OID, strings.ToUpper(identity.Identifier), MATCH_BY_ID, MATCH_BY_NAME, ANSIBLE_BASE, targetKind)
+		case ansible.AWS_ACCESS_KEY_IDENTITY:
+			credentialEdge = GenerateEdgeCustom(edgeKind, credential.OID, identity.Identifier, MATCH_BY_ID, MATCH_BY_ID, ANSIBLE_BASE, targetKind)
+		default:
+			credentialEdge = GenerateEdgeCustom(edgeKind, credential.OID, identity.Identifier, MATCH_BY_ID, MATCH_BY_NAME, ANSIBLE_BASE, targetKind)
+		}
+		credentialEdge.SetProperty("cloud_identity", identity.Identifier)
+		if identity.Tenant != "" {
+			credentialEdge.SetProperty("tenant", identity.Tenant)
+		}
+		graph.AddEdgeWithoutValidation(credentialEdge)
+	}
+
+	if len(unresolved) > 0 {
+		log.Warnf("Unable to resolve %d Azure application id(s), AzureHound data (--azurehound) is required: %s",
+			len(unresolved), strings.Join(unresolved, ", "))
+	}
+}
+
+func LinkAzure(graph *gopengraph.OpenGraph, azure bool, users map[int]*ansible.User) {
+
+	if azure {
+
+		log.Info("Linking Ansible and Entra ID Users.")
+		log.Warn("Do not forget to upload AzureHound graphing on BloodHound to leverage.")
+		edgeKind := "SyncedToATUser"
+
+		for _, user := range users {
+			if user.ExternalAccount == SOCIAL_VALUE {
+				// NOTE: AzureHound names Entra ID users after their UPN in uppercase,
+				// SAML/OIDC users are usually named after their UPN or carry it as their email.
+				principalNames := make(map[string]bool)
+				if strings.Contains(user.Username, "@") {
+					principalNames[strings.ToUpper(user.Username)] = true
+				}
+				if user.Email != "" {
+					principalNames[strings.ToUpper(user.Email)] = true
+				}
+				for principalName := range principalNames {
+					edge := GenerateEdgeCustom(edgeKind, principalName, user.OID, MATCH_BY_NAME, MATCH_BY_ID, AZURE_USER, ANSIBLE_BASE)
+					graph.AddEdgeWithoutValidation(edge)
 				}
 			}
 		}
 	} else {
-		log.Warn("Skipping linking Ansible and GitHub")
+		log.Warn("Skipping linking Ansible and Entra ID")
+	}
+}
+
+func LinkScm(graph *gopengraph.OpenGraph, enabledProviders []string, scmHosts map[string]string,
+	projects map[int]*ansible.Project, credentials map[int]*ansible.Credential) {
+
+	if len(enabledProviders) == 0 {
+		log.Warn("Skipping linking Ansible and SCM providers")
+		return
+	}
+
+	log.Infof("Linking Ansible and SCM providers (%s).", strings.Join(enabledProviders, ", "))
+	log.Warn("Do not forget to upload the SCM provider graphing on BloodHound to leverage.")
+
+	// NOTE: SCM credentials are linked to the providers of the projects using them.
+	credentialProviders := make(map[int][]ScmProvider)
+
+	if projects != nil {
+
+		log.Info("Linking Ansible projects and SCM repositories.")
+		edgeKind := "ATHasSourceControlUrl"
+
+		for _, project := range projects {
+			repository, provider, ok := ProjectRepository(project, scmHosts)
+			if !ok || !slices.Contains(enabledProviders, provider.Name) {
+				continue
+			}
+			edge := GenerateEdgeCustom(edgeKind, project.OID, repository.FullName(), MATCH_BY_ID, MATCH_BY_NAME, ANSIBLE_BASE, provider.RepositoryKind)
+			edge.SetProperty("scm_host", repository.Host)
+			graph.AddEdgeWithoutValidation(edge)
+
+			if project.Credential != 0 && !slices.ContainsFunc(credentialProviders[project.Credential],
+				func(p ScmProvider) bool { return p.Name == provider.Name }) {
+				credentialProviders[project.Credential] = append(credentialProviders[project.Credential], provider)
+			}
+		}
+	}
+
+	if credentials != nil {
+
+		log.Info("Linking Ansible credentials and SCM users.")
+		edgeKind := "ATIsCredentialOf"
+
+		for _, credential := range credentials {
+			if credential.Kind != CREDENTIAL_KIND {
+				continue
+			}
+			username, _ := credential.Inputs[CREDENTIAL_USERNAME].(string)
+			if username == "" {
+				continue
+			}
+			providers, ok := credentialProviders[credential.ID]
+			if !ok {
+				// NOTE: Credentials that are not used by a project are assumed to be GitHub ones.
+				if !slices.Contains(enabledProviders, GITHUB_PROVIDER) {
+					continue
+				}
+				provider, _ := FindScmProvider("github.com", nil)
+				providers = []ScmProvider{provider}
+			}
+			for _, provider := range providers {
+				edge := GenerateEdgeCustom(edgeKind, credential.OID, username, MATCH_BY_ID, MATCH_BY_NAME, ANSIBLE_BASE, provider.UserKind)
+				graph.AddEdgeWithoutValidation(edge)
+			}
+		}
+	}
+}
+
+func LinkWebhooks(graph *gopengraph.OpenGraph, enabledProviders []string, scmHosts map[string]string,
+	projects map[int]*ansible.Project, jobTemplates map[int]*ansible.JobTemplate,
+	workflowJobTemplates map[int]*ansible.WorkflowJobTemplate,
+	workflowJobTemplateNodes map[int]*ansible.WorkflowJobTemplateNode) {
+
+	if len(enabledProviders) == 0 {
+		log.Warn("Skipping linking SCM repositories and webhook templates")
+		return
+	}
+
+	log.Info("Linking SCM repositories and webhook templates.")
+	edgeKind := "ATTriggeredBy"
+
+	// NOTE: AWX/Tower does not store the repository sending the webhook, it is assumed
+	// to be the repository of the project used by the template.
+	jobTemplateRepository := func(jobTemplate *ansible.JobTemplate) (ScmRepository, ScmProvider, bool) {
+		if !gather.HasAccessTo(projects, jobTemplate.Project) {
+			return ScmRepository{}, ScmProvider{}, false
+		}
+		return ProjectRepository(projects[jobTemplate.Project], scmHosts)
+	}
+
+	linkTemplate := func(templateOID string, webhookService string, repository ScmRepository, provider ScmProvider) {
+		if WEBHOOK_SERVICES[webhookService] != provider.Name || !slices.Contains(enabledProviders, provider.Name) {
+			return
+		}
+		edge := GenerateEdgeCustom(edgeKind, repository.FullName(), templateOID, MATCH_BY_NAME, MATCH_BY_ID, provider.RepositoryKind, ANSIBLE_BASE)
+		edge.SetProperty("webhook_service", webhookService)
+		graph.AddEdgeWithoutValidation(edge)
+	}
+
+	for _, jobTemplate := range jobTemplates {
+		if jobTemplate.WebhookService == "" {
+			continue
+		}
+		if repository, provider, ok := jobTemplateRepository(jobTemplate); ok {
+			linkTemplate(jobTemplate.OID, jobTemplate.WebhookService, repository, provider)
+		}
+	}
+
+	// NOTE: The repository of a Workflow Job Template is only resolvable when all of
+	// its Job Templates use the same repository.
+	for _, workflowJobTemplate := range workflowJobTemplates {
+		if workflowJobTemplate.WebhookService == "" {
+			continue
+		}
+		repositories := make(map[string]ScmRepository)
+		var provider ScmProvider
+		for _, workflowJobTemplateNode := range workflowJobTemplateNodes {
+			if workflowJobTemplateNode.WorkflowJobTemplate != workflowJobTemplate.ID ||
+				!gather.HasAccessTo(jobTemplates, workflowJobTemplateNode.UnifiedJobTemplate) {
+				continue
+			}
+			if repository, nodeProvider, ok := jobTemplateRepository(jobTemplates[workflowJobTemplateNode.UnifiedJobTemplate]); ok {
+				repositories[repository.Host+"/"+repository.FullName()] = repository
+				provider = nodeProvider
+			}
+		}
+		if len(repositories) != 1 {
+			continue
+		}
+		for _, repository := range repositories {
+			linkTemplate(workflowJobTemplate.OID, workflowJobTemplate.WebhookService, repository, provider)
+		}
 	}
 }
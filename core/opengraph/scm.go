@@ -0,0 +1,159 @@
+package opengraph
+
+import (
+	"ansible-hound/core/ansible"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/log"
+)
+
+const GITHUB_PROVIDER = "github"
+const GITLAB_PROVIDER = "gitlab"
+const BITBUCKET_PROVIDER = "bitbucket"
+const AZURE_DEVOPS_PROVIDER = "azure_devops"
+
+// ScmProvider describes how the repositories and users of an SCM are named in BloodHound.
+type ScmProvider struct {
+	Name           string
+	Hosts          []string
+	RepositoryKind string
+	UserKind       string
+}
+
+// Providers of the webhook services supported by AWX/Tower.
+var WEBHOOK_SERVICES = map[string]string{
+	"github":       GITHUB_PROVIDER,
+	"gitlab":       GITLAB_PROVIDER,
+	"bitbucket_dc": BITBUCKET_PROVIDER,
+}
+
+var SCM_PROVIDERS = []ScmProvider{
+	{
+		Name:           GITHUB_PROVIDER,
+		Hosts:          []string{"github.com", "ssh.github.com"},
+		RepositoryKind: "GHRepository",
+		UserKind:       "GHUser",
+	},
+	{
+		Name:           GITLAB_PROVIDER,
+		Hosts:          []string{"gitlab.com"},
+		RepositoryKind: "GLProject",
+		UserKind:       "GLUser",
+	},
+	{
+		Name:           BITBUCKET_PROVIDER,
+		Hosts:          []string{"bitbucket.org"},
+		RepositoryKind: "BBRepository",
+		UserKind:       "BBUser",
+	},
+	{
+		Name:           AZURE_DEVOPS_PROVIDER,
+		Hosts:          []string{"dev.azure.com", "ssh.dev.azure.com", "vs-ssh.visualstudio.com"},
+		RepositoryKind: "ADORepository",
+		UserKind:       "ADOUser",
+	},
+}
+
+// ScmRepository is a repository parsed from an SCM URL. The owner can hold
+// several path components (EX: GitLab subgroups, Azure DevOps `organization/project`).
+type ScmRepository struct {
+	Host       string
+	Owner      string
+	Repository string
+}
+
+func (r ScmRepository) FullName() string {
+	return r.Owner + "/" + r.Repository
+}
+
+// Parses https, ssh and scp-style (`git@host:owner/repo.git`) SCM URLs.
+func ParseScmUrl(scmUrl string) (repository ScmRepository, err error) {
+
+	scmUrl = strings.TrimSpace(scmUrl)
+
+	var host, repositoryPath string
+	if strings.Contains(scmUrl, "://") {
+		parsed, err := url.Parse(scmUrl)
+		if err != nil {
+			return repository, err
+		}
+		host = parsed.Hostname()
+		repositoryPath = parsed.Path
+	} else {
+		// NOTE: scp-style URLs have no scheme, the path follows the first colon.
+		userHost, scpPath, ok := strings.Cut(scmUrl, ":")
+		if !ok {
+			return repository, fmt.Errorf("unsupported SCM URL `%s`", scmUrl)
+		}
+		_, host, ok = strings.Cut(userHost, "@")
+		if !ok {
+			host = userHost
+		}
+		repositoryPath = scpPath
+	}
+
+	host = strings.ToLower(host)
+	repositoryPath = strings.TrimSuffix(strings.Trim(repositoryPath, "/"), DOT_GIT_SCM_TYPE)
+	components := strings.Split(repositoryPath, "/")
+
+	switch {
+	case host == "dev.azure.com" || strings.HasSuffix(host, ".visualstudio.com") && host != "vs-ssh.visualstudio.com":
+		// NOTE: https://dev.azure.com/organization/project/_git/repo or https://organization.visualstudio.com/project/_git/repo
+		if organization, ok := strings.CutSuffix(host, ".visualstudio.com"); ok {
+			components = append([]string{organization}, components...)
+		}
+		components = slices.DeleteFunc(components, func(component string) bool { return component == "_git" })
+	case host == "ssh.dev.azure.com" || host == "vs-ssh.visualstudio.com":
+		// NOTE: git@ssh.dev.azure.com:v3/organization/project/repo
+		if len(components) > 0 && components[0] == "v3" {
+			components = components[1:]
+		}
+	case len(components) > 0 && components[0] == "scm":
+		// NOTE: Bitbucket Server, https://host/scm/project/repo.git
+		components = components[1:]
+	}
+
+	components = slices.DeleteFunc(components, func(component string) bool { return component == "" })
+	if host == "" || len(components) < 2 {
+		return repository, fmt.Errorf("unable to find the owner and repository of `%s`", scmUrl)
+	}
+
+	repository = ScmRepository{
+		Host:       host,
+		Owner:      strings.Join(components[:len(components)-1], "/"),
+		Repository: components[len(components)-1],
+	}
+
+	return repository, nil
+}
+
+// Returns the provider hosting the repository, custom hosts (EX: GitHub Enterprise,
+// self-managed GitLab) are mapped to a provider name through `scmHosts`.
+func FindScmProvider(host string, scmHosts map[string]string) (provider ScmProvider, ok bool) {
+	host = strings.ToLower(host)
+	providerName := scmHosts[host]
+	for _, provider := range SCM_PROVIDERS {
+		if provider.Name == providerName || slices.Contains(provider.Hosts, host) ||
+			provider.Name == AZURE_DEVOPS_PROVIDER && strings.HasSuffix(host, ".visualstudio.com") {
+			return provider, true
+		}
+	}
+	return provider, false
+}
+
+// Returns the repository of a Git project and the provider hosting it.
+func ProjectRepository(project *ansible.Project, scmHosts map[string]string) (repository ScmRepository, provider ScmProvider, ok bool) {
+	if project.ScmType != GIT_SCM_TYPE || project.ScmUrl == "" {
+		return repository, provider, false
+	}
+	repository, err := ParseScmUrl(project.ScmUrl)
+	if err != nil {
+		log.Debug(err)
+		return repository, provider, false
+	}
+	provider, ok = FindScmProvider(repository.Host, scmHosts)
+	return repository, provider, ok
+}
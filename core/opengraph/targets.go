@@ -0,0 +1,195 @@
+package opengraph
+
+import (
+	"ansible-hound/core/ansible"
+	"ansible-hound/core/gather"
+	"maps"
+	"strconv"
+
+	"github.com/Ramoreik/gopengraph"
+	"github.com/charmbracelet/log"
+)
+
+// Returns the hosts and groups the limit of a template is evaluated against. Smart inventories
+// have no groups, constructed inventories are evaluated against the groups of their inputs.
+func InventoryScope(inventory *ansible.Inventory, hosts map[int]*ansible.Host,
+	groups map[int]*ansible.Group) *ansible.HostScope {
+
+	scopeHosts := make(map[int]*ansible.Host)
+	scopeGroups := make(map[int]*ansible.Group)
+
+	switch inventory.Kind {
+	case ansible.SMART_INVENTORY_KIND:
+		scopeHosts = inventory.Hosts
+	case ansible.CONSTRUCTED_INVENTORY_KIND:
+		scopeHosts = inventory.Hosts
+		for id, group := range groups {
+			if _, ok := inventory.InputInventories[group.Inventory]; ok {
+				scopeGroups[id] = group
+			}
+			// NOTE: Groups created by the constructed inventory (`groups`, `keyed_groups`) hold
+			// copies of the input hosts, they are mapped back to the input hosts by name.
+			if group.Inventory == inventory.ID {
+				constructedGroup := *group
+				constructedGroup.Hosts = make(map[int]*ansible.Host)
+				for _, host := range group.Hosts {
+					for inputID, inputHost := range inventory.Hosts {
+						if inputHost.Name == host.Name {
+							constructedGroup.Hosts[inputID] = inputHost
+						}
+					}
+				}
+				scopeGroups[id] = &constructedGroup
+			}
+		}
+	default:
+		for id, host := range hosts {
+			if host.Inventory == inventory.ID {
+				scopeHosts[id] = host
+			}
+		}
+		for id, group := range groups {
+			if group.Inventory == inventory.ID {
+				scopeGroups[id] = group
+			}
+		}
+	}
+
+	return ansible.NewHostScope(scopeHosts, scopeGroups)
+}
+
+// Creates an `ATTargets` edge between templates and the hosts their limit selects in their
+// inventory. When the limit is prompted on launch, any host of the inventory can be targeted.
+// Templates whose playbook was analyzed only target the hosts of their plays and get an
+// `ATDelegatesTo` edge to the hosts their tasks are delegated to.
+func LinkTargets(graph *gopengraph.OpenGraph, inventories map[int]*ansible.Inventory,
+	hosts map[int]*ansible.Host, groups map[int]*ansible.Group,
+	jobTemplates map[int]*ansible.JobTemplate, workflowJobTemplates map[int]*ansible.WorkflowJobTemplate,
+	workflowJobTemplateNodes map[int]*ansible.WorkflowJobTemplateNode) {
+
+	scopes := make(map[int]*ansible.HostScope)
+	scope := func(inventoryID int) *ansible.HostScope {
+		if _, ok := scopes[inventoryID]; !ok {
+			scopes[inventoryID] = InventoryScope(inventories[inventoryID], hosts, groups)
+		}
+		return scopes[inventoryID]
+	}
+
+	log.Info("Linking Job Templates and their target Hosts.")
+	for _, jobTemplate := range jobTemplates {
+		if !gather.HasAccessTo(inventories, jobTemplate.Inventory) {
+			continue
+		}
+		linkTargets(graph, jobTemplate.OID, scope(jobTemplate.Inventory),
+			jobTemplate.Limit, jobTemplate.AskLimitOnLaunch, jobTemplate.PlaybookAnalysis)
+	}
+
+	log.Info("Linking Workflow Job Template Nodes and their target Hosts.")
+	for _, workflowJobTemplateNode := range workflowJobTemplateNodes {
+		if !gather.HasAccessTo(jobTemplates, workflowJobTemplateNode.UnifiedJobTemplate) {
+			continue
+		}
+		jobTemplate := jobTemplates[workflowJobTemplateNode.UnifiedJobTemplate]
+		workflowJobTemplate := workflowJobTemplates[workflowJobTemplateNode.WorkflowJobTemplate]
+
+		// NOTE: Prompted values are only used when the Job Template asks for them,
+		// values of the Workflow Job Template take precedence over those of the node.
+		inventory, limit, prompted := jobTemplate.Inventory, jobTemplate.Limit, false
+		if jobTemplate.AskInventoryOnLaunch {
+			if workflowJobTemplate != nil && workflowJobTemplate.Inventory != 0 {
+				inventory = workflowJobTemplate.Inventory
+			} else if workflowJobTemplateNode.Inventory != 0 {
+				inventory = workflowJobTemplateNode.Inventory
+			}
+		}
+		if jobTemplate.AskLimitOnLaunch {
+			if workflowJobTemplate != nil && workflowJobTemplate.Limit != "" {
+				limit = workflowJobTemplate.Limit
+			} else if workflowJobTemplateNode.Limit != "" {
+				limit = workflowJobTemplateNode.Limit
+			}
+			prompted = workflowJobTemplate != nil && workflowJobTemplate.AskLimitOnLaunch
+		}
+
+		if !gather.HasAccessTo(inventories, inventory) {
+			continue
+		}
+		linkTargets(graph, workflowJobTemplateNode.OID, scope(inventory), limit, prompted,
+			jobTemplate.PlaybookAnalysis)
+	}
+}
+
+// Links a template to the hosts it targets: the hosts of its plays, when its playbook was
+// analyzed, restricted by its limit.
+func linkTargets(graph *gopengraph.OpenGraph, templateOID string, scope *ansible.HostScope,
+	limit string, limitPromptOnLaunch bool, playbookAnalysis *ansible.PlaybookAnalysis) {
+
+	edgeKind := "ATTargets"
+
+	// NOTE: Patterns that cannot be evaluated do not hide the reach of the template, every host
+	// of the inventory is kept and the targets are flagged as inexact.
+	targets, exact := scope.Hosts, true
+	if playbookAnalysis != nil {
+		if playbookAnalysis.HostsResolvable() {
+			playTargets := make(map[int]*ansible.Host)
+			for _, pattern := range playbookAnalysis.Hosts {
+				matched, playExact, err := scope.MatchHostPattern(pattern)
+				if err != nil {
+					log.Warnf("Unable to evaluate the play hosts `%s`, targeting every host of the inventory: %s", pattern, err)
+					playTargets, exact = scope.Hosts, false
+					break
+				}
+				maps.Copy(playTargets, matched)
+				exact = exact && playExact
+			}
+			targets = playTargets
+		} else {
+			exact = false
+		}
+	}
+
+	if !limitPromptOnLaunch && limit != "" {
+		limitTargets, limitExact, err := scope.MatchHostPattern(limit)
+		if err != nil {
+			log.Warnf("Unable to evaluate the limit `%s`, targeting every host of the inventory: %s", limit, err)
+			limitTargets, limitExact = scope.Hosts, false
+		}
+		targets = intersectHosts(targets, limitTargets)
+		exact = exact && limitExact
+	}
+
+	for _, host := range targets {
+		edge := GenerateEdge(edgeKind, templateOID, host.OID)
+		edge.SetProperty("limit_prompt_on_launch", strconv.FormatBool(limitPromptOnLaunch))
+		AddEdge(graph, edge)
+	}
+
+	if templateNode := graph.GetNode(templateOID); templateNode != nil {
+		templateNode.SetProperty("target_hosts", strconv.Itoa(len(targets)))
+		templateNode.SetProperty("targets_exact", strconv.FormatBool(exact && !limitPromptOnLaunch))
+		templateNode.SetProperty("limit_prompt_on_launch", strconv.FormatBool(limitPromptOnLaunch))
+	}
+
+	// NOTE: Tasks delegated to a host of the inventory run on it whatever the limit is.
+	if playbookAnalysis != nil {
+		edgeKind = "ATDelegatesTo"
+		for _, delegateTo := range playbookAnalysis.DelegateTo {
+			for _, host := range scope.Hosts {
+				if host.Name == delegateTo {
+					edge := GenerateEdge(edgeKind, templateOID, host.OID)
+					AddEdge(graph, edge)
+				}
+			}
+		}
+	}
+}
+
+func intersectHosts(hosts map[int]*ansible.Host, others map[int]*ansible.Host) (intersection map[int]*ansible.Host) {
+	intersection = make(map[int]*ansible.Host)
+	for id, host := range hosts {
+		if _, ok := others[id]; ok {
+			intersection[id] = host
+		}
+	}
+	return intersection
+}
@@ -0,0 +1,105 @@
+package opengraph
+
+import (
+	"ansible-hound/core/ansible"
+	"cmp"
+	"slices"
+
+	"github.com/Ramoreik/gopengraph"
+	"github.com/charmbracelet/log"
+)
+
+const VARIABLE_SOURCE_SUFFIX = "_source"
+
+// Sets the effective connection variables of every Host, following the Ansible precedence:
+// `ansible.cfg` defaults, inventory variables, then group variables (parents before children, then ordered by
+// `ansible_group_priority` and name), then host variables. The origin of each variable
+// is stored in `<variable>_source`.
+func InheritVariables(graph *gopengraph.OpenGraph, inventories map[int]*ansible.Inventory,
+	groups map[int]*ansible.Group, hosts map[int]*ansible.Host) {
+
+	log.Info("Inheriting Group variables to Hosts.")
+
+	depths := groupDepths(groups)
+	hostGroups := make(map[int][]*ansible.Group)
+	for _, group := range groups {
+		for hostID := range group.DescendantHosts(groups) {
+			hostGroups[hostID] = append(hostGroups[hostID], group)
+		}
+	}
+
+	for _, host := range hosts {
+		hostNode := graph.GetNode(host.OID)
+		if hostNode == nil {
+			continue
+		}
+
+		effective := make(map[string]string)
+		sources := make(map[string]string)
+		apply := func(variables string, source string) {
+			for name, value := range ansible.ConnectionVariables(variables) {
+				effective[name] = value
+				sources[name] = source
+			}
+		}
+
+		if inventory, ok := inventories[host.Inventory]; ok {
+			apply(inventory.ConfigVariables, "ansible.cfg")
+			apply(inventory.Variables, "inventory")
+		}
+
+		// NOTE: Groups applied last take precedence.
+		memberOf := hostGroups[host.ID]
+		slices.SortFunc(memberOf, func(a *ansible.Group, b *ansible.Group) int {
+			return cmp.Or(
+				cmp.Compare(depths[a.ID], depths[b.ID]),
+				cmp.Compare(ansible.GroupPriority(a.Variables), ansible.GroupPriority(b.Variables)),
+				cmp.Compare(a.Name, b.Name),
+			)
+		})
+		for _, group := range memberOf {
+			apply(group.Variables, "group:"+group.Name)
+		}
+
+		apply(host.Variables, "host")
+
+		for name, value := range effective {
+			hostNode.SetProperty(name, value)
+			hostNode.SetProperty(name+VARIABLE_SOURCE_SUFFIX, sources[name])
+		}
+	}
+}
+
+// Returns the depth of each group, top-level groups have a depth of 1 and children
+// are one level deeper than their deepest parent.
+func groupDepths(groups map[int]*ansible.Group) (depths map[int]int) {
+	parents := make(map[int][]int)
+	for _, group := range groups {
+		for childID := range group.Children {
+			parents[childID] = append(parents[childID], group.ID)
+		}
+	}
+
+	depths = make(map[int]int)
+	var depth func(groupID int, visiting map[int]bool) int
+	depth = func(groupID int, visiting map[int]bool) int {
+		if d, ok := depths[groupID]; ok {
+			return d
+		}
+		if visiting[groupID] {
+			return 0
+		}
+		visiting[groupID] = true
+		d := 1
+		for _, parentID := range parents[groupID] {
+			d = max(d, depth(parentID, visiting)+1)
+		}
+		delete(visiting, groupID)
+		depths[groupID] = d
+		return d
+	}
+	for groupID := range groups {
+		depth(groupID, make(map[int]bool))
+	}
+	return depths
+}
@@ -63,6 +63,7 @@ if __name__ == "__main__":
     define_icon(url, jwt_token, "ATCredential", "key", "#94E16A")
     define_icon(url, jwt_token, "ATCredentialType", "gear", "#94E16A")
     define_icon(url, jwt_token, "ATHost", "desktop", "#E9E350")
+    define_icon(url, jwt_token, "ATInventorySource", "cloud", "#FF78F2")
     define_icon(url, jwt_token, "ATTeam", "people-group", "#724752")
     define_icon(url, jwt_token, "ATGroup", "object-group", "#159b7c")
     define_icon(url, jwt_token, "ATWorkflowJobTemplate", "circle-nodes", "#15369b")
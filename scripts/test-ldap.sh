@@ -0,0 +1,31 @@
+#!/bin/sh
+# Starts an OpenLDAP stand-in for the domain controller and runs the LDAP integration tests against it.
+
+set -e
+
+CONTAINER_NAME="ansiblehound-openldap"
+LDAP_PASSWORD="admin"
+# NOTE: OpenLDAP generates its certificate for the container hostname, the tests connect to the same name.
+LDAP_HOSTNAME="localhost"
+CA_FILE="$(mktemp)"
+
+docker run --rm -d --name "$CONTAINER_NAME" --hostname "$LDAP_HOSTNAME" -p 389:389 \
+    -e LDAP_ORGANISATION="Example" \
+    -e LDAP_DOMAIN="example.org" \
+    -e LDAP_ADMIN_PASSWORD="$LDAP_PASSWORD" \
+    osixia/openldap:1.5.0 > /dev/null
+trap 'docker stop "$CONTAINER_NAME" > /dev/null; rm -f "$CA_FILE"' EXIT
+
+echo "[*] Waiting for OpenLDAP to start."
+until docker exec "$CONTAINER_NAME" ldapsearch -x -H ldap://localhost -b dc=example,dc=org \
+    -D cn=admin,dc=example,dc=org -w "$LDAP_PASSWORD" > /dev/null 2>&1; do
+    sleep 1
+done
+
+docker cp "$CONTAINER_NAME":/container/service/slapd/assets/certs/ca.crt "$CA_FILE" > /dev/null
+
+AH_TEST_LDAP_HOST="ldap://$LDAP_HOSTNAME:389" \
+AH_TEST_LDAP_CA_FILE="$CA_FILE" \
+AH_TEST_LDAP_BIND_DN="cn=admin,dc=example,dc=org" \
+AH_TEST_LDAP_PASSWORD="$LDAP_PASSWORD" \
+    go test -tags integration -v ./core/gather/